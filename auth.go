@@ -0,0 +1,116 @@
+package cloudconfigclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// authorizer sets the authorization of a request to a Config Server.
+type authorizer interface {
+	authorize(ctx context.Context, req *http.Request) error
+	// scheme returns the authorization scheme, e.g. 'Bearer', for logging.
+	scheme() string
+}
+
+// invalidator is implemented by authorizers whose credentials can be refreshed. The credentials are invalidated when
+// a Config Server rejects them with a 401, so the next request is authorized with fresh credentials.
+type invalidator interface {
+	invalidate()
+}
+
+// tokenAuthorizer authorizes requests with an OAuth2 token. The token is cached until it expires or a Config Server
+// rejects it with a 401.
+//
+// Unlike the transport returned by clientcredentials.Config.Client, the token is retrieved with the context of the
+// request being authorized, so a cancelled request also cancels the token fetch.
+type tokenAuthorizer struct {
+	config *clientcredentials.Config
+	// client is used to retrieve the token from the config. If nil, http.DefaultClient is used.
+	client *http.Client
+	// source retrieves the token instead of the config, if set.
+	source func(ctx context.Context) (*oauth2.Token, error)
+	mu     sync.Mutex
+	token  *oauth2.Token
+}
+
+func (t *tokenAuthorizer) authorize(ctx context.Context, req *http.Request) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.token.Valid() {
+		token, err := t.fetch(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve token: %w", err)
+		}
+		t.token = token
+	}
+	t.token.SetAuthHeader(req)
+	return nil
+}
+
+func (t *tokenAuthorizer) scheme() string {
+	return "Bearer"
+}
+
+// setClient sets the client used to retrieve the token, e.g. after the TLS settings were applied to it.
+func (t *tokenAuthorizer) setClient(client *http.Client) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.client = client
+}
+
+// invalidate discards the cached token, e.g. because the Config Server revoked it before it expired.
+func (t *tokenAuthorizer) invalidate() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.token = nil
+}
+
+func (t *tokenAuthorizer) fetch(ctx context.Context) (*oauth2.Token, error) {
+	if t.source != nil {
+		token, err := t.source(ctx)
+		if err == nil && token == nil {
+			err = errors.New("token source returned no token")
+		}
+		return token, err
+	}
+	if t.client != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, t.client)
+	}
+	return t.config.Token(ctx)
+}
+
+// TokenSource creates a client for each Config Server that authorizes requests with the tokens of the
+// oauth2.TokenSource. A token is cached until it expires, then a new token is retrieved from the source. If a Config
+// Server responds with a 401, a new token is retrieved from the source and the request is sent once more, so the
+// source should not return the rejected token again.
+func TokenSource(client *http.Client, source oauth2.TokenSource, urls ...string) Option {
+	return func(c *Client) error {
+		if source == nil {
+			return errors.New("token source must not be nil")
+		}
+		return TokenFunc(client, func(ctx context.Context) (*oauth2.Token, error) {
+			return source.Token()
+		}, urls...)(c)
+	}
+}
+
+// TokenFunc is like TokenSource but retrieves the tokens by calling fn with the context of the request being
+// authorized.
+func TokenFunc(client *http.Client, fn func(ctx context.Context) (*oauth2.Token, error), urls ...string) Option {
+	return func(c *Client) error {
+		if fn == nil {
+			return errors.New("token function must not be nil")
+		}
+		auth := &tokenAuthorizer{source: fn}
+		for _, baseURL := range urls {
+			c.clients = append(c.clients, &HTTPClient{BaseURL: baseURL, Client: client, auth: auth})
+		}
+		return nil
+	}
+}
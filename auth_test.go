@@ -0,0 +1,312 @@
+package cloudconfigclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Piszmog/cloudconfigclient/v3"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func newTokenServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOAuth2_TokenCached(t *testing.T) {
+	var tokenRequests atomic.Int32
+	tokenServer := newTokenServer(t, func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"token","token_type":"bearer","expires_in":3600}`))
+	})
+	configServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(configurationSource))
+	}))
+	defer configServer.Close()
+
+	client, err := cloudconfigclient.New(cloudconfigclient.OAuth2(configServer.URL, "clientId", "secret", tokenServer.URL))
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = client.GetConfiguration("appName", "profile")
+		require.NoError(t, err)
+	}
+	require.Equal(t, int32(1), tokenRequests.Load())
+}
+
+func TestOAuth2_TokenFetchUsesRequestContext(t *testing.T) {
+	release := make(chan struct{})
+	tokenServer := newTokenServer(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+	// cleanups run in reverse order, so the handler is released before the server is closed
+	t.Cleanup(func() { close(release) })
+	client, err := cloudconfigclient.New(cloudconfigclient.OAuth2("http://config", "clientId", "secret", tokenServer.URL))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.GetConfigurationContext(ctx, "appName", "profile")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// countingTransport counts the requests sent through it to each path.
+type countingTransport struct {
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	if c.requests == nil {
+		c.requests = map[string]int{}
+	}
+	c.requests[req.URL.Path]++
+	c.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestOAuth2WithSettings(t *testing.T) {
+	tests := []struct {
+		name      string
+		authStyle oauth2.AuthStyle
+		basicAuth bool
+	}{
+		{
+			name:      "Auth In Header",
+			authStyle: oauth2.AuthStyleInHeader,
+			basicAuth: true,
+		},
+		{
+			name:      "Auth In Params",
+			authStyle: oauth2.AuthStyleInParams,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var tokenRequests atomic.Int32
+			tokenServer := newTokenServer(t, func(w http.ResponseWriter, r *http.Request) {
+				tokenRequests.Add(1)
+				require.NoError(t, r.ParseForm())
+				require.Equal(t, "read write", r.PostForm.Get("scope"))
+				require.Equal(t, "config-server", r.PostForm.Get("audience"))
+				username, password, ok := r.BasicAuth()
+				require.Equal(t, test.basicAuth, ok)
+				if ok {
+					require.Equal(t, "clientId", username)
+					require.Equal(t, "secret", password)
+				} else {
+					require.Equal(t, "clientId", r.PostForm.Get("client_id"))
+					require.Equal(t, "secret", r.PostForm.Get("client_secret"))
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"access_token":"token","token_type":"bearer","expires_in":3600}`))
+			})
+			configServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+				_, _ = w.Write([]byte(configurationSource))
+			}))
+			defer configServer.Close()
+
+			transport := &countingTransport{}
+			client, err := cloudconfigclient.New(
+				cloudconfigclient.OAuth2WithSettings(cloudconfigclient.OAuth2Settings{
+					ClientID:       "clientId",
+					ClientSecret:   "secret",
+					TokenURL:       tokenServer.URL + "/oauth/token",
+					Scopes:         []string{"read", "write"},
+					EndpointParams: url.Values{"audience": {"config-server"}},
+					AuthStyle:      test.authStyle,
+					Client:         &http.Client{Transport: transport},
+				}, configServer.URL, configServer.URL),
+				cloudconfigclient.WithFailover(cloudconfigclient.DefaultFailoverPolicy()),
+			)
+			require.NoError(t, err)
+			for i := 0; i < 2; i++ {
+				_, err = client.GetConfiguration("appName", "profile")
+				require.NoError(t, err)
+			}
+			require.Equal(t, int32(1), tokenRequests.Load())
+			require.Equal(t, map[string]int{"/oauth/token": 1, "/appName/profile": 2}, transport.requests)
+		})
+	}
+}
+
+func TestOAuth2WithSettings_NoURLs(t *testing.T) {
+	_, err := cloudconfigclient.New(cloudconfigclient.OAuth2WithSettings(cloudconfigclient.OAuth2Settings{}))
+	require.EqualError(t, err, "at least one Config Server URL must be provided")
+}
+
+func TestTokenSource(t *testing.T) {
+	var authorizations []string
+	httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+		authorizations = append(authorizations, req.Header.Get("Authorization"))
+		return NewMockHttpResponse(http.StatusOK, configurationSource)
+	})
+	tokens := 0
+	source := oauth2.ReuseTokenSource(nil, tokenSourceFunc(func() (*oauth2.Token, error) {
+		tokens++
+		// the token expires within the expiry delta of oauth2.Token, so every request retrieves a new token
+		return &oauth2.Token{AccessToken: "token" + strconv.Itoa(tokens), Expiry: time.Now().Add(time.Second)}, nil
+	}))
+	client, err := cloudconfigclient.New(cloudconfigclient.TokenSource(httpClient, source, "http://localhost:8888"))
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = client.GetConfiguration("appName", "profile")
+		require.NoError(t, err)
+	}
+	require.Equal(t, []string{"Bearer token1", "Bearer token2"}, authorizations)
+}
+
+type tokenSourceFunc func() (*oauth2.Token, error)
+
+func (f tokenSourceFunc) Token() (*oauth2.Token, error) {
+	return f()
+}
+
+func TestTokenFunc(t *testing.T) {
+	tests := []struct {
+		name   string
+		fn     func(ctx context.Context) (*oauth2.Token, error)
+		tokens int
+		err    string
+	}{
+		{
+			name: "Token Cached",
+			fn: func(ctx context.Context) (*oauth2.Token, error) {
+				require.Equal(t, "value", ctx.Value(contextKey{}))
+				return &oauth2.Token{AccessToken: "token", Expiry: time.Now().Add(time.Hour)}, nil
+			},
+			tokens: 1,
+		},
+		{
+			name: "Error",
+			fn: func(ctx context.Context) (*oauth2.Token, error) {
+				return nil, errors.New("sidecar unavailable")
+			},
+			tokens: 2,
+			err:    "failed to authorize request for http://localhost:8888/appName/profile: failed to retrieve token: sidecar unavailable",
+		},
+		{
+			name: "No Token",
+			fn: func(ctx context.Context) (*oauth2.Token, error) {
+				return nil, nil
+			},
+			tokens: 2,
+			err:    "failed to authorize request for http://localhost:8888/appName/profile: failed to retrieve token: token source returned no token",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+				require.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+				return NewMockHttpResponse(http.StatusOK, configurationSource)
+			})
+			tokens := 0
+			client, err := cloudconfigclient.New(cloudconfigclient.TokenFunc(httpClient, func(ctx context.Context) (*oauth2.Token, error) {
+				tokens++
+				return test.fn(ctx)
+			}, "http://localhost:8888"))
+			require.NoError(t, err)
+
+			ctx := context.WithValue(context.Background(), contextKey{}, "value")
+			for i := 0; i < 2; i++ {
+				_, err = client.GetConfigurationContext(ctx, "appName", "profile")
+				if test.err != "" {
+					require.EqualError(t, err, test.err)
+				} else {
+					require.NoError(t, err)
+				}
+			}
+			require.Equal(t, test.tokens, tokens)
+		})
+	}
+}
+
+func TestTokenSource_Nil(t *testing.T) {
+	_, err := cloudconfigclient.New(cloudconfigclient.TokenSource(&http.Client{}, nil, "http://localhost:8888"))
+	require.EqualError(t, err, "token source must not be nil")
+	_, err = cloudconfigclient.New(cloudconfigclient.TokenFunc(&http.Client{}, nil, "http://localhost:8888"))
+	require.EqualError(t, err, "token function must not be nil")
+}
+
+func TestOAuth2_TokenRevoked(t *testing.T) {
+	tests := []struct {
+		name      string
+		rejectAll bool
+		requests  []string
+		err       string
+	}{
+		{
+			name:     "New Token Accepted",
+			requests: []string{"Bearer token1", "Bearer token2"},
+		},
+		{
+			name:      "New Token Rejected",
+			rejectAll: true,
+			requests:  []string{"Bearer token1", "Bearer token2"},
+			err:       "server responded with status code '401' and body ''",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var valid string
+			var tokenRequests int
+			rejectAll := false
+			tokenServer := newTokenServer(t, func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				tokenRequests++
+				valid = "token" + strconv.Itoa(tokenRequests)
+				token := valid
+				mu.Unlock()
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"access_token":"` + token + `","token_type":"bearer","expires_in":3600}`))
+			})
+			var requests []string
+			configServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				requests = append(requests, r.Header.Get("Authorization"))
+				if rejectAll || r.Header.Get("Authorization") != "Bearer "+valid {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				_, _ = w.Write([]byte(configurationSource))
+			}))
+			defer configServer.Close()
+
+			client, err := cloudconfigclient.New(cloudconfigclient.OAuth2(configServer.URL, "clientId", "secret", tokenServer.URL))
+			require.NoError(t, err)
+			_, err = client.GetConfiguration("appName", "profile")
+			require.NoError(t, err)
+
+			// the Config Server revokes the cached token before it expires
+			mu.Lock()
+			requests = nil
+			valid = ""
+			rejectAll = test.rejectAll
+			mu.Unlock()
+			_, err = client.GetConfiguration("appName", "profile")
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, test.requests, requests)
+			require.Equal(t, 2, tokenRequests)
+		})
+	}
+}
@@ -0,0 +1,390 @@
+package cloudconfigclient
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const springCloudConfigPrefix = "spring.cloud.config."
+
+// Bootstrap is the Config Server configuration of a Spring bootstrap file (e.g. bootstrap.yml or
+// application.properties), read from the spring.cloud.config.* keys.
+type Bootstrap struct {
+	// URIs are the base URLs of the Config Servers (spring.cloud.config.uri).
+	URIs []string
+	// Username is the basic authentication username (spring.cloud.config.username).
+	Username string
+	// Password is the basic authentication password (spring.cloud.config.password).
+	Password string
+	// Label is the label of the configuration (spring.cloud.config.label).
+	Label string
+	// Name is the name of the application (spring.cloud.config.name, defaulting to spring.application.name).
+	Name string
+	// Profiles are the profiles of the application (spring.cloud.config.profile, defaulting to
+	// spring.profiles.active).
+	Profiles []string
+	// FailFast is whether the application fails to start when the configuration cannot be retrieved
+	// (spring.cloud.config.fail-fast). Nil if not set.
+	FailFast *bool
+	// Retry is the policy used when FailFast is true (spring.cloud.config.retry.*).
+	Retry RetryPolicy
+	// Header contains the headers sent with every request (spring.cloud.config.headers.*).
+	Header http.Header
+	// TLS configures the TLS of the requests (spring.cloud.config.tls.*).
+	TLS BootstrapTLS
+	// OAuth2 configures the client credentials flow (spring.cloud.config.client.oauth2.*). Nil if not set.
+	OAuth2 *OAuth2Settings
+	// UnknownKeys are the spring.cloud.config.* keys that are not supported, sorted.
+	UnknownKeys []string
+}
+
+// BootstrapTLS is the TLS configuration of a Bootstrap. The key store and trust store must be PEM encoded.
+type BootstrapTLS struct {
+	// Enabled is whether the TLS configuration is used (spring.cloud.config.tls.enabled).
+	Enabled bool
+	// KeyStore is the PEM file containing the client certificate and its key (spring.cloud.config.tls.key-store).
+	KeyStore string
+	// TrustStore is the PEM file containing the trusted certificate authorities (spring.cloud.config.tls.trust-store).
+	TrustStore string
+}
+
+// NewFromBootstrap creates a Client from a Spring bootstrap file, so Go and Java applications can share the same
+// bootstrap file. The options are applied after the options of the bootstrap file. See ReadBootstrap.
+func NewFromBootstrap(path string, options ...Option) (*Client, Bootstrap, error) {
+	bootstrap, err := ReadBootstrap(path)
+	if err != nil {
+		return nil, bootstrap, err
+	}
+	client, err := New(append(bootstrap.Options(&http.Client{}), options...)...)
+	return client, bootstrap, err
+}
+
+// ReadBootstrap reads the Config Server configuration of a Spring bootstrap file. The file is either YAML (.yml or
+// .yaml) or a .properties file. Spring's relaxed binding (e.g. failFast or fail_fast) and placeholders of environment
+// variables (e.g. ${CONFIG_PASSWORD} or ${CONFIG_PASSWORD:default}) are supported.
+//
+// YAML documents that are only activated for some profiles are ignored. Unsupported spring.cloud.config.* keys are
+// reported in Bootstrap.UnknownKeys.
+func ReadBootstrap(path string) (Bootstrap, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Bootstrap{}, fmt.Errorf("failed to read bootstrap file: %w", err)
+	}
+	defer f.Close()
+	var properties map[string]string
+	switch ext := filepath.Ext(path); ext {
+	case ".yml", ".yaml":
+		properties, err = readYAMLProperties(f)
+	case ".properties":
+		properties, err = readProperties(f)
+	default:
+		return Bootstrap{}, fmt.Errorf("unsupported bootstrap file extension '%s'", ext)
+	}
+	if err != nil {
+		return Bootstrap{}, fmt.Errorf("failed to read bootstrap file %s: %w", path, err)
+	}
+	return bindBootstrap(properties)
+}
+
+// Options returns the options of the Client equivalent to the Bootstrap. The Config Servers use the client and
+// authorize requests with OAuth2 if configured, basic authentication if a username is set, or nothing otherwise.
+//
+// Like Spring, the next URI is tried when a Config Server cannot be reached or responds with a 5xx. If FailFast is true the Retry policy is used, and if FailFast is false or not set
+// (spring.cloud.config.fail-fast defaults to false) the configuration is optional, see WithOptional. The empty
+// configuration is then logged as a warning with the logger of the Client, or slog.Default. Since optional
+// and fail-fast are mutually exclusive, FailFast must be true to combine the options with WithFailFast.
+func (b Bootstrap) Options(client *http.Client) []Option {
+	uris := b.URIs
+	if len(uris) == 0 {
+		uris = []string{defaultSpringCloudConfigURI}
+	}
+	var options []Option
+	switch {
+	case b.OAuth2 != nil:
+		settings := *b.OAuth2
+		settings.Client = client
+		options = append(options, OAuth2WithSettings(settings, uris...))
+	case b.Username != "":
+		options = append(options, Basic(client, b.Username, b.Password, uris...))
+	default:
+		options = append(options, Local(client, uris...))
+	}
+	if len(uris) > 1 {
+		options = append(options, WithFailover(DefaultFailoverPolicy()))
+	}
+	if b.FailFast != nil && *b.FailFast {
+		options = append(options, WithRetry(b.Retry))
+	} else {
+		options = append(options, WithOptional(nil))
+	}
+	if len(b.Header) > 0 {
+		options = append(options, WithHeader(b.Header))
+	}
+	if b.TLS.Enabled {
+		if b.TLS.KeyStore != "" {
+			options = append(options, WithClientCertificate(b.TLS.KeyStore, b.TLS.KeyStore))
+		}
+		if b.TLS.TrustStore != "" {
+			options = append(options, WithCAFile(b.TLS.TrustStore))
+		}
+	}
+	return options
+}
+
+// Application returns the SpringApplication whose configuration is retrieved. Like Spring, the name defaults to
+// 'application' and the profiles default to 'default'.
+func (b Bootstrap) Application() SpringApplication {
+	app := SpringApplication{Name: b.Name, Profiles: b.Profiles, Label: b.Label}
+	if app.Name == "" {
+		app.Name = defaultSpringApplicationName
+	}
+	if len(app.Profiles) == 0 {
+		app.Profiles = []string{defaultSpringProfile}
+	}
+	return app
+}
+
+func bindBootstrap(properties map[string]string) (Bootstrap, error) {
+	bootstrap := Bootstrap{Retry: DefaultRetryPolicy()}
+	var applicationName string
+	var activeProfiles []string
+	uris := map[int]string{}
+	for _, key := range slices.Sorted(maps.Keys(properties)) {
+		value := expandPlaceholders(properties[key])
+		canonical := canonicalKey(key)
+		switch canonical {
+		case "spring.application.name":
+			applicationName = value
+			continue
+		case "spring.profiles.active":
+			activeProfiles = splitList(value)
+			continue
+		}
+		if !strings.HasPrefix(canonical, springCloudConfigPrefix) {
+			continue
+		}
+		if err := bootstrap.bind(key, canonical[len(springCloudConfigPrefix):], value, uris); err != nil {
+			return Bootstrap{}, fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+	}
+	for _, index := range slices.Sorted(maps.Keys(uris)) {
+		bootstrap.URIs = append(bootstrap.URIs, uris[index])
+	}
+	if bootstrap.Name == "" {
+		bootstrap.Name = applicationName
+	}
+	if len(bootstrap.Profiles) == 0 {
+		bootstrap.Profiles = activeProfiles
+	}
+	return bootstrap, nil
+}
+
+var indexedKeyRegex = regexp.MustCompile(`^(.+)\[(\d+)]$`)
+
+// bind sets the field of the Bootstrap of the spring.cloud.config.* key. The name is the canonical key without the
+// prefix.
+func (b *Bootstrap) bind(key string, name string, value string, uris map[int]string) error {
+	if matches := indexedKeyRegex.FindStringSubmatch(name); matches != nil && matches[1] == "uri" {
+		index, err := strconv.Atoi(matches[2])
+		if err != nil {
+			return err
+		}
+		uris[index] = value
+		return nil
+	}
+	if strings.HasPrefix(name, "headers.") {
+		if b.Header == nil {
+			b.Header = http.Header{}
+		}
+		// the header name is taken from the original key, since the canonical key is lowercase
+		b.Header.Add(strings.SplitN(key, ".", 5)[4], value)
+		return nil
+	}
+	var err error
+	switch name {
+	case "uri":
+		for i, uri := range splitList(value) {
+			uris[i] = uri
+		}
+	case "username":
+		b.Username = value
+	case "password":
+		b.Password = value
+	case "label":
+		b.Label = value
+	case "name":
+		b.Name = value
+	case "profile":
+		b.Profiles = splitList(value)
+	case "failfast":
+		var failFast bool
+		failFast, err = strconv.ParseBool(value)
+		b.FailFast = &failFast
+	case "retry.maxattempts":
+		b.Retry.MaxAttempts, err = strconv.Atoi(value)
+	case "retry.initialinterval":
+		b.Retry.InitialInterval, err = parseMillis(value)
+	case "retry.maxinterval":
+		b.Retry.MaxInterval, err = parseMillis(value)
+	case "retry.multiplier":
+		b.Retry.Multiplier, err = strconv.ParseFloat(value, 64)
+	case "tls.enabled":
+		b.TLS.Enabled, err = strconv.ParseBool(value)
+	case "tls.keystore":
+		b.TLS.KeyStore = value
+	case "tls.truststore":
+		b.TLS.TrustStore = value
+	case "tls.keystoretype", "tls.truststoretype":
+		if !strings.EqualFold(value, "PEM") {
+			err = fmt.Errorf("unsupported store type '%s', only PEM is supported", value)
+		}
+	case "client.oauth2.clientid":
+		b.oauth2().ClientID = value
+	case "client.oauth2.clientsecret":
+		b.oauth2().ClientSecret = value
+	case "client.oauth2.accesstokenuri":
+		b.oauth2().TokenURL = value
+	case "client.oauth2.scope":
+		b.oauth2().Scopes = splitList(value)
+	default:
+		b.UnknownKeys = append(b.UnknownKeys, key)
+	}
+	return err
+}
+
+func (b *Bootstrap) oauth2() *OAuth2Settings {
+	if b.OAuth2 == nil {
+		b.OAuth2 = &OAuth2Settings{}
+	}
+	return b.OAuth2
+}
+
+// canonicalKey converts the key to the canonical form of Spring's relaxed binding, e.g. spring.cloud.config.failFast
+// and spring.cloud.config.fail_fast both become spring.cloud.config.failfast.
+func canonicalKey(key string) string {
+	return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(key))
+}
+
+// parseMillis parses the duration in milliseconds, as used by Spring, or a duration with a unit, e.g. 1s.
+func parseMillis(value string) (time.Duration, error) {
+	if millis, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(millis) * time.Millisecond, nil
+	}
+	return time.ParseDuration(value)
+}
+
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+var placeholderRegex = regexp.MustCompile(`\$\{([^}:]+)(?::([^}]*))?}`)
+
+// expandPlaceholders replaces the placeholders of environment variables, e.g. ${NAME} or ${NAME:default}.
+func expandPlaceholders(value string) string {
+	return placeholderRegex.ReplaceAllStringFunc(value, func(placeholder string) string {
+		matches := placeholderRegex.FindStringSubmatch(placeholder)
+		if env, ok := os.LookupEnv(matches[1]); ok {
+			return env
+		}
+		return matches[2]
+	})
+}
+
+// readYAMLProperties flattens the YAML documents to properties, e.g. spring.cloud.config.uri or
+// spring.cloud.config.uri[0]. Documents activated only for some profiles are ignored.
+func readYAMLProperties(r io.Reader) (map[string]string, error) {
+	properties := map[string]string{}
+	decoder := yaml.NewDecoder(r)
+	for {
+		var document map[string]any
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			return properties, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		documentProperties := map[string]string{}
+		flatten("", document, documentProperties)
+		if isProfileSpecific(documentProperties) {
+			continue
+		}
+		maps.Copy(properties, documentProperties)
+	}
+}
+
+func isProfileSpecific(properties map[string]string) bool {
+	for key := range properties {
+		switch canonical := canonicalKey(key); {
+		case canonical == "spring.profiles", strings.HasPrefix(canonical, "spring.config.activate."):
+			return true
+		}
+	}
+	return false
+}
+
+func flatten(prefix string, value any, properties map[string]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flatten(key, child, properties)
+		}
+	case []any:
+		for i, child := range v {
+			flatten(prefix+"["+strconv.Itoa(i)+"]", child, properties)
+		}
+	case nil:
+		properties[prefix] = ""
+	default:
+		properties[prefix] = fmt.Sprint(v)
+	}
+}
+
+// readProperties reads a .properties file. Lines starting with '#' or '!' are comments. A key is separated from its
+// value by '=' or ':', and a line ending with '\' continues on the next line.
+func readProperties(r io.Reader) (map[string]string, error) {
+	properties := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	var line bytes.Buffer
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if line.Len() == 0 && (text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, "!")) {
+			continue
+		}
+		if strings.HasSuffix(text, `\`) {
+			line.WriteString(strings.TrimSuffix(text, `\`))
+			continue
+		}
+		line.WriteString(text)
+		key, value := line.String(), ""
+		if i := strings.IndexAny(key, "=:"); i >= 0 {
+			key, value = key[:i], key[i+1:]
+		}
+		properties[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		line.Reset()
+	}
+	return properties, scanner.Err()
+}
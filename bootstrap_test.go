@@ -0,0 +1,270 @@
+package cloudconfigclient_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Piszmog/cloudconfigclient/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func writeBootstrap(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestReadBootstrap(t *testing.T) {
+	failFast := true
+	retry := cloudconfigclient.DefaultRetryPolicy()
+	retry.MaxAttempts = 3
+	retry.InitialInterval = 500 * time.Millisecond
+	retry.MaxInterval = 5 * time.Second
+	retry.Multiplier = 2
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected cloudconfigclient.Bootstrap
+	}{
+		{
+			name: "YAML",
+			file: "bootstrap.yml",
+			content: `spring:
+  application:
+    name: appName
+  profiles:
+    active: dev,cloud
+  cloud:
+    config:
+      uri:
+        - http://config1:8888
+        - http://config2:8888
+      username: user
+      password: ${BOOTSTRAP_TEST_PASSWORD:default}
+      label: main
+      fail-fast: true
+      retry:
+        max-attempts: 3
+        initialInterval: 500
+        max_interval: 5s
+        multiplier: 2
+      headers:
+        X-Config-Token: token
+      tls:
+        enabled: true
+        key-store: /etc/certs/client.pem
+        trust-store: /etc/certs/ca.pem
+      request-read-timeout: 1000
+server:
+  port: 8080
+---
+spring:
+  config:
+    activate:
+      on-profile: prod
+  cloud:
+    config:
+      uri: http://prod:8888
+`,
+			expected: cloudconfigclient.Bootstrap{
+				URIs:        []string{"http://config1:8888", "http://config2:8888"},
+				Username:    "user",
+				Password:    "secret",
+				Label:       "main",
+				Name:        "appName",
+				Profiles:    []string{"dev", "cloud"},
+				FailFast:    &failFast,
+				Retry:       retry,
+				Header:      http.Header{"X-Config-Token": {"token"}},
+				TLS:         cloudconfigclient.BootstrapTLS{Enabled: true, KeyStore: "/etc/certs/client.pem", TrustStore: "/etc/certs/ca.pem"},
+				UnknownKeys: []string{"spring.cloud.config.request-read-timeout"},
+			},
+		},
+		{
+			name: "Properties",
+			file: "application.properties",
+			content: `# the Config Server
+spring.cloud.config.uri=http://config1:8888,\
+  http://config2:8888
+spring.cloud.config.name: appName
+spring.cloud.config.profile=dev
+spring.application.name=ignored
+spring.cloud.config.client.oauth2.client-id=client
+spring.cloud.config.client.oauth2.client-secret=${BOOTSTRAP_TEST_MISSING:}
+spring.cloud.config.client.oauth2.access-token-uri=http://token
+spring.cloud.config.client.oauth2.scope=read,write
+spring.cloud.config.discovery.enabled=false
+`,
+			expected: cloudconfigclient.Bootstrap{
+				URIs:        []string{"http://config1:8888", "http://config2:8888"},
+				Name:        "appName",
+				Profiles:    []string{"dev"},
+				Retry:       cloudconfigclient.DefaultRetryPolicy(),
+				OAuth2:      &cloudconfigclient.OAuth2Settings{ClientID: "client", TokenURL: "http://token", Scopes: []string{"read", "write"}},
+				UnknownKeys: []string{"spring.cloud.config.discovery.enabled"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("BOOTSTRAP_TEST_PASSWORD", "secret")
+			bootstrap, err := cloudconfigclient.ReadBootstrap(writeBootstrap(t, test.file, test.content))
+			require.NoError(t, err)
+			require.Equal(t, test.expected, bootstrap)
+		})
+	}
+}
+
+func TestReadBootstrap_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		err     string
+	}{
+		{
+			name:    "Invalid Value",
+			file:    "bootstrap.yml",
+			content: "spring.cloud.config.fail-fast: sometimes",
+			err:     `invalid value for spring.cloud.config.fail-fast: strconv.ParseBool: parsing "sometimes": invalid syntax`,
+		},
+		{
+			name:    "Unsupported Store",
+			file:    "bootstrap.properties",
+			content: "spring.cloud.config.tls.key-store-type=PKCS12",
+			err:     "invalid value for spring.cloud.config.tls.key-store-type: unsupported store type 'PKCS12', only PEM is supported",
+		},
+		{
+			name: "Unsupported Extension",
+			file: "bootstrap.json",
+			err:  "unsupported bootstrap file extension '.json'",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := cloudconfigclient.ReadBootstrap(writeBootstrap(t, test.file, test.content))
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestNewFromBootstrap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/appName/dev/main", r.URL.Path)
+		require.Equal(t, "token", r.Header.Get("X-Config-Token"))
+		username, password, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "user", username)
+		require.Equal(t, "secret", password)
+		_, _ = w.Write([]byte(configurationSource))
+	}))
+	defer server.Close()
+	path := writeBootstrap(t, "bootstrap.yml", `spring:
+  cloud:
+    config:
+      uri: `+server.URL+`
+      name: appName
+      profile: dev
+      label: main
+      username: user
+      password: secret
+      headers:
+        X-Config-Token: token
+`)
+
+	client, bootstrap, err := cloudconfigclient.NewFromBootstrap(path)
+	require.NoError(t, err)
+	app := bootstrap.Application()
+	source, err := client.GetConfigurationWithLabelContext(context.Background(), app.Label, app.Name, app.Profiles...)
+	require.NoError(t, err)
+	require.Equal(t, "testConfig", source.Name)
+}
+
+func TestNewFromBootstrap_FailFast(t *testing.T) {
+	tests := []struct {
+		name     string
+		failFast string
+		optional bool
+	}{
+		{
+			name:     "Not Set",
+			optional: true,
+		},
+		{
+			name:     "False",
+			failFast: "      fail-fast: false\n",
+			optional: true,
+		},
+		{
+			name:     "True",
+			failFast: "      fail-fast: true\n      retry:\n        max-attempts: 1\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeBootstrap(t, "bootstrap.yml", `spring:
+  cloud:
+    config:
+      uri: http://127.0.0.1:1
+`+test.failFast)
+
+			client, _, err := cloudconfigclient.NewFromBootstrap(path)
+			require.NoError(t, err)
+			source, err := client.GetConfiguration("appName", "default")
+			if test.optional {
+				require.NoError(t, err)
+				require.Equal(t, cloudconfigclient.Source{Name: "appName", Profiles: []string{"default"}}, source)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestNewFromBootstrap_MultipleURIs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(configurationSource))
+	}))
+	defer server.Close()
+	// the first Config Server refuses connections
+	path := writeBootstrap(t, "bootstrap.yml", `spring:
+  cloud:
+    config:
+      uri: http://127.0.0.1:1,`+server.URL+`
+`)
+
+	client, _, err := cloudconfigclient.NewFromBootstrap(path)
+	require.NoError(t, err)
+	source, err := client.GetConfiguration("appName", "default")
+	require.NoError(t, err)
+	require.Equal(t, "testConfig", source.Name)
+}
+
+func TestNewFromBootstrap_OptionalWarning(t *testing.T) {
+	logger, buf := newLogger()
+	defaultLogger := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+	path := writeBootstrap(t, "bootstrap.yml", `spring:
+  cloud:
+    config:
+      uri: http://127.0.0.1:1,http://127.0.0.1:2
+`)
+
+	client, _, err := cloudconfigclient.NewFromBootstrap(path)
+	require.NoError(t, err)
+	_, err = client.GetConfiguration("appName", "default")
+	require.NoError(t, err)
+	// without a logger, the empty configuration is logged with the default logger
+	event := findEvent(t, logEvents(t, buf), "using empty configuration")
+	require.Equal(t, "WARN", event["level"])
+	require.Equal(t, "appName", event["application"])
+	require.Contains(t, event["error"], "127.0.0.1:2")
+}
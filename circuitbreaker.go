@@ -0,0 +1,189 @@
+package cloudconfigclient
+
+import (
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of the circuit breaker of a Config Server.
+type CircuitState int
+
+const (
+	// CircuitClosed is the state of a healthy Config Server. Requests are sent to the Config Server.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen is the state of a failing Config Server. The Config Server is skipped.
+	CircuitOpen
+	// CircuitHalfOpen is the state of a failing Config Server whose open timeout elapsed. A single request is sent to
+	// the Config Server to probe whether it recovered.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrCircuitOpen is the error recorded for a Config Server that was skipped because its circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+const (
+	defaultFailureThreshold = 5
+	defaultOpenTimeout      = 30 * time.Second
+)
+
+// CircuitBreakerSettings configures the circuit breaker of each Config Server.
+type CircuitBreakerSettings struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit breaker. Defaults to 5.
+	FailureThreshold int
+	// OpenTimeout is how long the circuit breaker stays open before a probe is let through. Defaults to 30 seconds.
+	OpenTimeout time.Duration
+}
+
+// WithCircuitBreaker wraps each Config Server in a circuit breaker. A Config Server is skipped while its circuit
+// breaker is open, so requests do not wait on a Config Server that is known to be down.
+//
+// Transport errors, 5xx responses and requests that run out the deadline of the context count as failures. A 404 or any
+// other response closes the circuit breaker.
+func WithCircuitBreaker(settings CircuitBreakerSettings) Option {
+	return func(c *Client) error {
+		if settings.FailureThreshold < 1 {
+			settings.FailureThreshold = defaultFailureThreshold
+		}
+		if settings.OpenTimeout <= 0 {
+			settings.OpenTimeout = defaultOpenTimeout
+		}
+		c.breakerSettings = &settings
+		c.breakers = map[string]*circuitBreaker{}
+		return nil
+	}
+}
+
+// CircuitBreakerStatus is the status of the circuit breaker of a Config Server.
+type CircuitBreakerStatus struct {
+	// State is the current state of the circuit breaker.
+	State CircuitState
+	// ConsecutiveFailures is the number of failures since the last successful request.
+	ConsecutiveFailures int
+	// OpenedAt is when the circuit breaker last opened.
+	OpenedAt time.Time
+}
+
+// CircuitBreakers returns the status of the circuit breaker of each Config Server, keyed by the base URL of the Config
+// Server. Returns nil if the Client was not created with WithCircuitBreaker.
+func (c *Client) CircuitBreakers() map[string]CircuitBreakerStatus {
+	if c.breakerSettings == nil {
+		return nil
+	}
+	servers := c.servers()
+	statuses := make(map[string]CircuitBreakerStatus, len(servers))
+	for _, client := range servers {
+		statuses[client.BaseURL] = c.breaker(client).status()
+	}
+	return statuses
+}
+
+// breaker returns the circuit breaker of the Config Server. Returns nil if circuit breakers are not enabled.
+func (c *Client) breaker(client *HTTPClient) *circuitBreaker {
+	if c.breakerSettings == nil {
+		return nil
+	}
+	c.breakersMu.Lock()
+	defer c.breakersMu.Unlock()
+	breaker, ok := c.breakers[client.BaseURL]
+	if !ok {
+		breaker = &circuitBreaker{settings: *c.breakerSettings}
+		c.breakers[client.BaseURL] = breaker
+	}
+	return breaker
+}
+
+type circuitBreaker struct {
+	settings CircuitBreakerSettings
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// allow reports whether a request may be sent to the Config Server. In the half-open state, only a single probe is
+// allowed until its outcome is recorded.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case CircuitOpen:
+		if time.Since(b.openedAt) < b.settings.OpenTimeout {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		return true
+	case CircuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record records the outcome of a request allowed by allow.
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if !isServerFailure(err) {
+		b.state = CircuitClosed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.settings.FailureThreshold {
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// release gives up a request allowed by allow without recording an outcome, e.g. when the request was cancelled.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *circuitBreaker) status() CircuitBreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state := b.state
+	if state == CircuitOpen && time.Since(b.openedAt) >= b.settings.OpenTimeout {
+		state = CircuitHalfOpen
+	}
+	return CircuitBreakerStatus{State: state, ConsecutiveFailures: b.failures, OpenedAt: b.openedAt}
+}
+
+// isServerFailure reports whether the error indicates the Config Server is unavailable, i.e. a transport error or a
+// 5xx response.
+func isServerFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var serverErr *ServerError
+	if errors.As(err, &serverErr) {
+		return serverErr.StatusCode >= 500
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
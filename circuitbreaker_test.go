@@ -0,0 +1,212 @@
+package cloudconfigclient_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Piszmog/cloudconfigclient/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitState_String(t *testing.T) {
+	require.Equal(t, "closed", cloudconfigclient.CircuitClosed.String())
+	require.Equal(t, "open", cloudconfigclient.CircuitOpen.String())
+	require.Equal(t, "half-open", cloudconfigclient.CircuitHalfOpen.String())
+	require.Equal(t, "unknown", cloudconfigclient.CircuitState(10).String())
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	var requested []string
+	responses := okResponses()
+	delete(responses, "server1")
+	httpClient := NewMockHostsHttpClient(&requested, responses)
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(httpClient, "http://server1", "http://server2"),
+		cloudconfigclient.WithFailover(cloudconfigclient.DefaultFailoverPolicy()),
+		cloudconfigclient.WithCircuitBreaker(cloudconfigclient.CircuitBreakerSettings{FailureThreshold: 2, OpenTimeout: 50 * time.Millisecond}),
+	)
+	require.NoError(t, err)
+
+	// two consecutive failures open the circuit breaker of server1
+	for i := 0; i < 2; i++ {
+		_, err = client.GetConfiguration("appName", "profile")
+		require.NoError(t, err)
+	}
+	require.Equal(t, []string{"server1", "server2", "server1", "server2"}, requested)
+	statuses := client.CircuitBreakers()
+	require.Equal(t, cloudconfigclient.CircuitOpen, statuses["http://server1"].State)
+	require.Equal(t, 2, statuses["http://server1"].ConsecutiveFailures)
+	require.Equal(t, cloudconfigclient.CircuitClosed, statuses["http://server2"].State)
+
+	// server1 is skipped while open
+	requested = nil
+	_, err = client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+	require.Equal(t, []string{"server2"}, requested)
+
+	// the probe fails, so the circuit breaker opens again
+	time.Sleep(60 * time.Millisecond)
+	require.Equal(t, cloudconfigclient.CircuitHalfOpen, client.CircuitBreakers()["http://server1"].State)
+	requested = nil
+	_, err = client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+	require.Equal(t, []string{"server1", "server2"}, requested)
+	require.Equal(t, cloudconfigclient.CircuitOpen, client.CircuitBreakers()["http://server1"].State)
+
+	// the probe succeeds, so the circuit breaker closes
+	time.Sleep(60 * time.Millisecond)
+	responses["server1"] = okResponses()["server1"]
+	requested = nil
+	_, err = client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+	require.Equal(t, []string{"server1"}, requested)
+	statuses = client.CircuitBreakers()
+	require.Equal(t, cloudconfigclient.CircuitClosed, statuses["http://server1"].State)
+	require.Zero(t, statuses["http://server1"].ConsecutiveFailures)
+}
+
+func TestWithCircuitBreaker_AllOpen(t *testing.T) {
+	var requested []string
+	httpClient := NewMockHostsHttpClient(&requested, nil)
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(httpClient, "http://server1"),
+		cloudconfigclient.WithFailover(cloudconfigclient.DefaultFailoverPolicy()),
+		cloudconfigclient.WithCircuitBreaker(cloudconfigclient.CircuitBreakerSettings{FailureThreshold: 1}),
+	)
+	require.NoError(t, err)
+
+	_, err = client.GetConfiguration("appName", "profile")
+	require.Error(t, err)
+	_, err = client.GetConfiguration("appName", "profile")
+	require.ErrorIs(t, err, cloudconfigclient.ErrCircuitOpen)
+	require.Equal(t, "failed to retrieve from every Config Server: http://server1: circuit breaker is open", err.Error())
+	require.Len(t, requested, 1)
+}
+
+func TestWithCircuitBreaker_SingleProbe(t *testing.T) {
+	var mu sync.Mutex
+	var requested []string
+	probing := make(chan struct{})
+	release := make(chan struct{})
+	server1Up := false
+	httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+		mu.Lock()
+		requested = append(requested, req.URL.Host)
+		up := server1Up
+		mu.Unlock()
+		if req.URL.Host == "server1" {
+			if !up {
+				return nil
+			}
+			close(probing)
+			<-release
+		}
+		return NewMockHttpResponse(http.StatusOK, configurationSource)
+	})
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(httpClient, "http://server1", "http://server2"),
+		cloudconfigclient.WithFailover(cloudconfigclient.DefaultFailoverPolicy()),
+		cloudconfigclient.WithCircuitBreaker(cloudconfigclient.CircuitBreakerSettings{FailureThreshold: 1, OpenTimeout: 10 * time.Millisecond}),
+	)
+	require.NoError(t, err)
+	_, err = client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	server1Up = true
+	requested = nil
+	mu.Unlock()
+	done := make(chan error)
+	go func() {
+		_, probeErr := client.GetConfiguration("appName", "profile")
+		done <- probeErr
+	}()
+	<-probing
+	// the probe is in flight, so server1 is skipped
+	_, err = client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+	close(release)
+	require.NoError(t, <-done)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"server1", "server2"}, requested)
+	require.Equal(t, cloudconfigclient.CircuitClosed, client.CircuitBreakers()["http://server1"].State)
+}
+
+func TestWithCircuitBreaker_DeadlineExceeded(t *testing.T) {
+	// the Config Server hangs until the caller gives up
+	httpClient := &http.Client{Transport: cloudconfigclient.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})}
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(httpClient, "http://server1"),
+		cloudconfigclient.WithCircuitBreaker(cloudconfigclient.CircuitBreakerSettings{FailureThreshold: 2}),
+	)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		_, err = client.GetConfigurationContext(ctx, "appName", "profile")
+		cancel()
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+	require.Eventually(t, func() bool {
+		return client.CircuitBreakers()["http://server1"].State == cloudconfigclient.CircuitOpen
+	}, time.Second, time.Millisecond)
+
+	// the open circuit breaker fails fast instead of waiting for the deadline again
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = client.GetConfigurationContext(ctx, "appName", "profile")
+	require.ErrorIs(t, err, cloudconfigclient.ErrCircuitOpen)
+	require.Less(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestWithCircuitBreaker_CallerCancelled(t *testing.T) {
+	httpClient := &http.Client{Transport: cloudconfigclient.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})}
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(httpClient, "http://server1"),
+		cloudconfigclient.WithCircuitBreaker(cloudconfigclient.CircuitBreakerSettings{FailureThreshold: 1}),
+	)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err = client.GetConfigurationContext(ctx, "appName", "profile")
+	require.ErrorIs(t, err, context.Canceled)
+	// a request cancelled by the caller says nothing about the Config Server
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, cloudconfigclient.CircuitClosed, client.CircuitBreakers()["http://server1"].State)
+}
+
+func TestWithCircuitBreaker_NotFoundIsNotAFailure(t *testing.T) {
+	var requested []string
+	httpClient := NewMockHostsHttpClient(&requested, map[string]func() *http.Response{
+		"server1": func() *http.Response { return NewMockHttpResponse(http.StatusNotFound, "") },
+	})
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(httpClient, "http://server1"),
+		cloudconfigclient.WithCircuitBreaker(cloudconfigclient.CircuitBreakerSettings{FailureThreshold: 1}),
+	)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = client.GetConfiguration("appName", "profile")
+		require.Error(t, err)
+	}
+	require.Len(t, requested, 3)
+	require.Equal(t, cloudconfigclient.CircuitClosed, client.CircuitBreakers()["http://server1"].State)
+}
+
+func TestClient_CircuitBreakers_Disabled(t *testing.T) {
+	client, err := cloudconfigclient.New(cloudconfigclient.Local(&http.Client{}, "http://server1"))
+	require.NoError(t, err)
+	require.Nil(t, client.CircuitBreakers())
+}
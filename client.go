@@ -5,11 +5,17 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/Piszmog/cfservices"
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 )
 
@@ -24,7 +30,34 @@ const (
 
 // Client contains the clients of the Config Servers.
 type Client struct {
-	clients []*HTTPClient
+	clients    []*HTTPClient
+	retry      *RetryPolicy
+	failover   FailoverPolicy
+	strategy   Strategy
+	hedgeDelay time.Duration
+
+	optional  bool
+	onWarning func(err error)
+	failFast  *failFastSettings
+	firstLoad atomic.Bool
+
+	breakerSettings *CircuitBreakerSettings
+	breakersMu      sync.Mutex
+	breakers        map[string]*circuitBreaker
+
+	tls        *tlsSettings
+	middleware *middlewareSettings
+
+	logger *slog.Logger
+
+	header      http.Header
+	headerFuncs []func(ctx context.Context) (http.Header, error)
+
+	serversMu   sync.RWMutex
+	discoveries []*discovery
+	discovered  [][]*HTTPClient
+	stopRefresh context.CancelFunc
+	refreshes   sync.WaitGroup
 }
 
 // New creates a new Client based on the provided options. A Client can be configured to communicate with
@@ -32,30 +65,86 @@ type Client struct {
 //
 // At least one option must be provided.
 func New(options ...Option) (*Client, error) {
-	var clients []*HTTPClient
 	if len(options) == 0 {
 		return nil, errors.New("at least one option must be provided")
 	}
+	c := &Client{failover: DefaultFailoverPolicy()}
 	for _, option := range options {
-		if err := option(&clients); err != nil {
+		if err := option(c); err != nil {
 			return nil, err
 		}
 	}
-	return &Client{clients: clients}, nil
+	for _, client := range c.clients {
+		if err := c.prepare(client); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.startDiscovery(); err != nil {
+		return nil, err
+	}
+	return c, nil
 }
 
-// Option creates a slice of httpClients per Config Server instance.
-type Option func(*[]*HTTPClient) error
+// prepare applies the Client wide settings to the HTTPClient of a Config Server. Settings already set on the
+// HTTPClient are left as is.
+func (c *Client) prepare(client *HTTPClient) error {
+	if client.Retry == nil {
+		client.Retry = c.retry
+	}
+	if client.Logger == nil {
+		client.Logger = c.logger
+	}
+	var header http.Header
+	for key, values := range c.header {
+		if _, ok := client.Header[key]; !ok {
+			if header == nil {
+				// the headers of the caller are copied, as they may be in use by requests in flight
+				header = client.Header.Clone()
+				if header == nil {
+					header = http.Header{}
+				}
+			}
+			header[key] = values
+		}
+	}
+	if header != nil {
+		client.Header = header
+	}
+	if client.HeaderFunc == nil && len(c.headerFuncs) > 0 {
+		client.HeaderFunc = c.computeHeader
+	}
+	if c.middleware.wraps(client.Client) {
+		return nil
+	}
+	if c.tls != nil {
+		tlsClient, err := c.tls.client(client.Client)
+		if err != nil {
+			return fmt.Errorf("failed to configure TLS for %s: %w", redactURL(client.BaseURL), err)
+		}
+		client.Client = tlsClient
+		if token, ok := client.auth.(*tokenAuthorizer); ok && token.client != tlsClient {
+			token.setClient(tlsClient)
+		}
+	}
+	if c.middleware != nil {
+		client.Client = c.middleware.client(client.Client)
+	}
+	return nil
+}
+
+// Option configures a Client. An Option either adds the httpClients of Config Server instances or configures how the
+// Client communicates with them.
+type Option func(*Client) error
 
 // LocalEnv creates a clients for a locally running Config Servers. The URLs to the Config Servers are acquired from the
 // environment variable 'CONFIG_SERVER_URLS'.
 func LocalEnv(client *http.Client) Option {
-	return func(clients *[]*HTTPClient) error {
+	return func(c *Client) error {
 		httpClients, err := newLocalClientFromEnv(client)
 		if err != nil {
 			return err
 		}
-		*clients = append(*clients, httpClients...)
+		c.clients = append(c.clients, httpClients...)
 		return nil
 	}
 }
@@ -70,17 +159,28 @@ func newLocalClientFromEnv(client *http.Client) ([]*HTTPClient, error) {
 
 // Local creates a clients for a locally running Config Servers.
 func Local(client *http.Client, urls ...string) Option {
-	return func(clients *[]*HTTPClient) error {
-		*clients = append(*clients, newSimpleClient(client, "", urls)...)
+	return func(c *Client) error {
+		c.clients = append(c.clients, newSimpleClient(client, "", urls)...)
 		return nil
 	}
 }
 
 // Basic creates a clients for a Config Server based on the provided basic authentication information.
 func Basic(client *http.Client, username, password string, urls ...string) Option {
-	return func(clients *[]*HTTPClient) error {
-		auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
-		*clients = append(*clients, newSimpleClient(client, auth, urls)...)
+	return func(c *Client) error {
+		c.clients = append(c.clients, newSimpleClient(client, basicAuthorization(username, password), urls)...)
+		return nil
+	}
+}
+
+func basicAuthorization(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
+
+// Bearer creates a clients for a Config Server that authorizes requests with the static bearer token.
+func Bearer(client *http.Client, token string, urls ...string) Option {
+	return func(c *Client) error {
+		c.clients = append(c.clients, newSimpleClient(client, "Bearer "+token, urls)...)
 		return nil
 	}
 }
@@ -99,7 +199,7 @@ func newSimpleClient(client *http.Client, auth string, urls []string) []*HTTPCli
 //
 // The service 'p.config-server' is search for first. If not found, 'p-config-server' is searched for.
 func DefaultCFService() Option {
-	return func(clients *[]*HTTPClient) error {
+	return func(c *Client) error {
 		services, err := cfservices.GetServices()
 		if err != nil {
 			return fmt.Errorf("failed to parse 'VCAP_SERVICES': %w", err)
@@ -119,7 +219,7 @@ func DefaultCFService() Option {
 				return err
 			}
 		}
-		*clients = append(*clients, httpClients...)
+		c.clients = append(c.clients, httpClients...)
 		return nil
 	}
 }
@@ -128,7 +228,7 @@ func DefaultCFService() Option {
 // variable 'VCAP_SERVICES' provides a JSON. The JSON should contain the entry matching the specified name. This
 // entry and used to build an OAuth Client.
 func CFService(service string) Option {
-	return func(clients *[]*HTTPClient) error {
+	return func(c *Client) error {
 		services, err := cfservices.GetServices()
 		if err != nil {
 			return fmt.Errorf("failed to parse 'VCAP_SERVICES': %w", err)
@@ -137,7 +237,7 @@ func CFService(service string) Option {
 		if err != nil {
 			return err
 		}
-		*clients = append(*clients, httpClients...)
+		c.clients = append(c.clients, httpClients...)
 		return nil
 	}
 }
@@ -149,22 +249,77 @@ func newCloudClientForService(name string, services map[string][]cfservices.Serv
 	}
 	clients := make([]*HTTPClient, len(creds.Credentials))
 	for i, cred := range creds.Credentials {
-		clients[i] = &HTTPClient{BaseURL: cred.Uri, Client: newOAuth2Client(cred.ClientId, cred.ClientSecret, cred.AccessTokenUri)}
+		clients[i] = newOAuth2Client(cred.Uri, cred.ClientId, cred.ClientSecret, cred.AccessTokenUri)
 	}
 	return clients, nil
 }
 
 // OAuth2 creates a Client for a Config Server based on the provided OAuth2.0 information.
+//
+// The token is retrieved with the context of the request to the Config Server, so cancelling a request also cancels
+// the token fetch. If the Config Server responds with a 401, e.g. because the token was revoked, a new token is
+// retrieved and the request is sent once more.
 func OAuth2(baseURL string, clientID string, secret string, tokenURI string) Option {
-	return func(clients *[]*HTTPClient) error {
-		*clients = append(*clients, &HTTPClient{BaseURL: baseURL, Client: newOAuth2Client(clientID, secret, tokenURI)})
+	return func(c *Client) error {
+		c.clients = append(c.clients, newOAuth2Client(baseURL, clientID, secret, tokenURI))
 		return nil
 	}
 }
 
-func newOAuth2Client(clientID string, secret string, tokenURI string) *http.Client {
-	config := newOAuth2Config(clientID, secret, tokenURI)
-	return config.Client(context.Background())
+// OAuth2Settings configures the client credentials flow used to authorize requests to Config Servers.
+type OAuth2Settings struct {
+	// ClientID is the application's ID.
+	ClientID string
+	// ClientSecret is the application's secret.
+	ClientSecret string
+	// TokenURL is the URL of the token endpoint of the OAuth2 Server.
+	TokenURL string
+	// Scopes are the scopes requested with the token.
+	Scopes []string
+	// EndpointParams are additional parameters sent to the token endpoint, e.g. an audience.
+	EndpointParams url.Values
+	// AuthStyle is how the client credentials are sent to the token endpoint. Defaults to auto-detecting it.
+	AuthStyle oauth2.AuthStyle
+	// Client is used for the requests to the Config Servers and the token endpoint, e.g. to configure a proxy. If
+	// nil, a new http.Client is used.
+	Client *http.Client
+}
+
+// OAuth2WithSettings is like OAuth2 but provides full control over the client credentials flow. A Client is created
+// for each URL. The Config Servers share the same token.
+func OAuth2WithSettings(settings OAuth2Settings, urls ...string) Option {
+	return func(c *Client) error {
+		if len(urls) == 0 {
+			return errors.New("at least one Config Server URL must be provided")
+		}
+		client := settings.Client
+		if client == nil {
+			client = &http.Client{}
+		}
+		auth := &tokenAuthorizer{
+			config: &clientcredentials.Config{
+				ClientID:       settings.ClientID,
+				ClientSecret:   settings.ClientSecret,
+				TokenURL:       settings.TokenURL,
+				Scopes:         settings.Scopes,
+				EndpointParams: settings.EndpointParams,
+				AuthStyle:      settings.AuthStyle,
+			},
+			client: settings.Client,
+		}
+		for _, baseURL := range urls {
+			c.clients = append(c.clients, &HTTPClient{BaseURL: baseURL, Client: client, auth: auth})
+		}
+		return nil
+	}
+}
+
+func newOAuth2Client(baseURL string, clientID string, secret string, tokenURI string) *HTTPClient {
+	return &HTTPClient{
+		BaseURL: baseURL,
+		Client:  &http.Client{},
+		auth:    &tokenAuthorizer{config: newOAuth2Config(clientID, secret, tokenURI)},
+	}
 }
 
 func newOAuth2Config(clientID string, secret string, tokenURI string) *clientcredentials.Config {
@@ -1,13 +1,16 @@
 package cloudconfigclient
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Source is the application's source configurations. It con contain zero to n number of property sources.
@@ -46,10 +49,11 @@ type PropertySourceHandler func(propertySource PropertySource)
 // handle boilerplate for-loop code and filtering of non-configuration files.
 //
 // Config Server may return other configurations (e.g. credhub property sources) that contain no configurations
-// (PropertySource.Source is empty).
+// (PropertySource.Source is empty). Property sources from a Vault backend are not files and are not handled, see
+// VaultPropertySources.
 func (s *Source) HandlePropertySources(handler PropertySourceHandler) {
 	for _, propertySource := range s.PropertySources {
-		if len(filepath.Ext(propertySource.Name)) > 0 {
+		if len(filepath.Ext(propertySource.Name)) > 0 && !propertySource.IsVault() {
 			handler(propertySource)
 		}
 	}
@@ -152,43 +156,100 @@ type Configuration interface {
 	// GetConfiguration retrieves the configurations/property sources of an application based on the name of the application
 	// and the profiles of the application.
 	GetConfiguration(applicationName string, profiles ...string) (Source, error)
+	// GetConfigurationContext is like GetConfiguration but uses the provided context for the requests.
+	GetConfigurationContext(ctx context.Context, applicationName string, profiles ...string) (Source, error)
 	// GetConfigurationWithLabel retrieves the configurations/property sources of an application based on the name of the application
 	// and the profiles of the application and the label.
 	GetConfigurationWithLabel(label string, applicationName string, profiles ...string) (Source, error)
+	// GetConfigurationWithLabelContext is like GetConfigurationWithLabel but uses the provided context for the requests.
+	GetConfigurationWithLabelContext(ctx context.Context, label string, applicationName string, profiles ...string) (Source, error)
 }
 
 // GetConfiguration retrieves the configurations/property sources of an application based on the name of the application
 // and the profiles of the application.
 func (c *Client) GetConfiguration(applicationName string, profiles ...string) (Source, error) {
-	var source Source
-	paths := []string{applicationName, joinProfiles(profiles)}
-	for _, client := range c.clients {
-		if err := client.GetResource(paths, nil, &source); err != nil {
-			if errors.Is(err, ErrResourceNotFound) {
-				continue
-			}
-			return Source{}, err
-		}
-		return source, nil
-	}
-	return Source{}, fmt.Errorf("failed to find configuration for application %s with profiles %s", applicationName, profiles)
+	return c.GetConfigurationContext(context.Background(), applicationName, profiles...)
+}
+
+// GetConfigurationContext is like GetConfiguration but uses the provided context for the requests. If the context is
+// cancelled, the remaining Config Servers are not tried.
+func (c *Client) GetConfigurationContext(ctx context.Context, applicationName string, profiles ...string) (Source, error) {
+	return c.getConfiguration(
+		ctx,
+		[]string{applicationName, joinProfiles(profiles)},
+		Source{Name: applicationName, Profiles: profiles},
+		fmt.Errorf("failed to find configuration for application %s with profiles %s", applicationName, profiles),
+	)
 }
 
 // GetConfigurationWithLabel retrieves the configurations/property sources of an application based on the name of the application
 // and the profiles of the application and the label.
 func (c *Client) GetConfigurationWithLabel(label string, applicationName string, profiles ...string) (Source, error) {
-	var source Source
-	paths := []string{applicationName, joinProfiles(profiles), label}
-	for _, client := range c.clients {
-		if err := client.GetResource(paths, nil, &source); err != nil {
-			if errors.Is(err, ErrResourceNotFound) {
-				continue
-			}
-			return Source{}, err
+	return c.GetConfigurationWithLabelContext(context.Background(), label, applicationName, profiles...)
+}
+
+// GetConfigurationWithLabelContext is like GetConfigurationWithLabel but uses the provided context for the requests. If
+// the context is cancelled, the remaining Config Servers are not tried.
+func (c *Client) GetConfigurationWithLabelContext(ctx context.Context, label string, applicationName string, profiles ...string) (Source, error) {
+	return c.getConfiguration(
+		ctx,
+		[]string{applicationName, joinProfiles(profiles), label},
+		Source{Name: applicationName, Profiles: profiles, Label: label},
+		fmt.Errorf("failed to find configuration for application %s with profiles %s and label %s", applicationName, profiles, label),
+	)
+}
+
+// getConfiguration retrieves the configuration at the paths. If the Client is optional and no Config Server was
+// reachable, the empty Source is returned instead of an error. notFoundErr is returned when every Config Server
+// responded with a 404.
+func (c *Client) getConfiguration(ctx context.Context, paths []string, empty Source, notFoundErr error) (Source, error) {
+	ctx, logger := c.requestLogger(ctx, configurationAttrs(empty)...)
+	source, err := c.loadConfiguration(ctx, logger, paths, notFoundErr)
+	if err != nil && c.optional && ctx.Err() == nil && (err == notFoundErr || unavailable(err)) {
+		if c.logger == nil && c.onWarning == nil {
+			logger = withAttrs(slog.Default(), configurationAttrs(empty))
 		}
-		return source, nil
+		logger.WarnContext(ctx, "using empty configuration", slog.Any("error", err))
+		c.warn(fmt.Errorf("using empty configuration for application %s: %w", empty.Name, err))
+		return empty, nil
+	}
+	return source, err
+}
+
+// loadConfiguration retrieves the configuration at the paths. If the Client is fail-fast, the first configuration
+// retrieved by the Client is retried until the fail-fast window elapses.
+func (c *Client) loadConfiguration(ctx context.Context, logger *slog.Logger, paths []string, notFoundErr error) (Source, error) {
+	if c.failFast == nil || !c.firstLoad.CompareAndSwap(false, true) {
+		return c.fetchConfiguration(ctx, logger, paths, notFoundErr)
+	}
+	deadline := time.Now().Add(c.failFast.window)
+	for {
+		source, err := c.fetchConfiguration(ctx, logger, paths, notFoundErr)
+		if err == nil || ctx.Err() != nil || time.Now().Add(c.failFast.interval).After(deadline) {
+			return source, err
+		}
+		logger.WarnContext(ctx, "failed to load the configuration, retrying until the fail-fast window elapses",
+			slog.Duration("interval", c.failFast.interval),
+			slog.Any("error", err),
+		)
+		if sleepErr := sleep(ctx, c.failFast.interval); sleepErr != nil {
+			return Source{}, errors.Join(err, sleepErr)
+		}
+	}
+}
+
+func (c *Client) fetchConfiguration(ctx context.Context, logger *slog.Logger, paths []string, notFoundErr error) (Source, error) {
+	source, found, err := fetch(ctx, c, logger, func(ctx context.Context, client *HTTPClient) (source Source, err error) {
+		err = client.GetResourceContext(ctx, paths, nil, &source)
+		return source, err
+	})
+	if err != nil {
+		return Source{}, err
+	}
+	if !found {
+		return Source{}, notFoundErr
 	}
-	return Source{}, fmt.Errorf("failed to find configuration for application %s with profiles %s and label %s", applicationName, profiles, label)
+	return source, nil
 }
 
 func joinProfiles(profiles []string) string {
@@ -1,11 +1,12 @@
 package cloudconfigclient_test
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"testing"
 
-	"github.com/Piszmog/cloudconfigclient/v2"
+	"github.com/Piszmog/cloudconfigclient/v3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -388,3 +389,25 @@ type nestedStruct struct {
 	StringVal string `json:"stringVal"`
 	IntVal    int    `json:"intVal"`
 }
+
+func TestClient_GetConfigurationContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var requestedURLs []string
+	httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+		requestedURLs = append(requestedURLs, req.URL.String())
+		// cancel after the first Config Server responds so the second one is never tried
+		cancel()
+		return NewMockHttpResponse(http.StatusNotFound, "")
+	})
+	client, err := cloudconfigclient.New(cloudconfigclient.Local(httpClient, "http://localhost:8888", "http://localhost:8889"))
+	require.NoError(t, err)
+
+	_, err = client.GetConfigurationContext(ctx, "appName", "profile")
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, []string{"http://localhost:8888/appName/profile"}, requestedURLs)
+
+	_, err = client.GetConfigurationWithLabelContext(ctx, "master", "appName", "profile")
+	require.ErrorIs(t, err, context.Canceled)
+	require.Len(t, requestedURLs, 1)
+}
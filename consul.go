@@ -0,0 +1,212 @@
+package cloudconfigclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultConsulAddress       = "http://127.0.0.1:8500"
+	defaultConsulService       = "configserver"
+	defaultConsulWaitTime      = 5 * time.Minute
+	defaultConsulRetryInterval = time.Second
+	defaultConsulMinInterval   = time.Second
+)
+
+// ConsulSettings configures the discovery of Config Servers from a Consul catalog.
+type ConsulSettings struct {
+	// Address is the address of the Consul agent. Defaults to 'http://127.0.0.1:8500'.
+	Address string
+	// Service is the name the Config Servers are registered with. Defaults to 'configserver'.
+	Service string
+	// Tag filters the instances by a tag, if set.
+	Tag string
+	// Datacenter is the datacenter to query. Defaults to the datacenter of the Consul agent.
+	Datacenter string
+	// Token is the ACL token sent in the X-Consul-Token header, if set.
+	Token string
+	// Watch is whether to watch the instances with blocking queries, so membership changes are picked up as soon as
+	// they happen.
+	Watch bool
+	// WaitTime is the maximum duration of a blocking query. Defaults to 5 minutes. The timeout of the http.Client, if
+	// any, must be greater.
+	WaitTime time.Duration
+	// RetryInterval is the wait before watching again after a blocking query failed. Defaults to 1 second.
+	RetryInterval time.Duration
+	// MinInterval is the minimum duration between the starts of two blocking queries, so the Consul agent is not
+	// flooded when a query returns immediately. Defaults to 1 second.
+	MinInterval time.Duration
+	// OnError is called when a blocking query fails. The previously found Config Servers are kept.
+	OnError func(err error)
+}
+
+// Consul discovers the Config Servers from the healthy instances of a service registered with Consul. The client is
+// used for the requests to Consul and the Config Servers.
+//
+// The URL of a Config Server is built from the address and port of its instance. The instance is served with https
+// if it has the 'secure=true' tag or metadata, and its 'configPath' tag or metadata is appended to the URL.
+//
+// If Watch is set, the Client must be closed to stop watching the instances.
+func Consul(client *http.Client, settings ConsulSettings) Option {
+	return func(c *Client) error {
+		if settings.Address == "" {
+			settings.Address = defaultConsulAddress
+		}
+		if settings.Service == "" {
+			settings.Service = defaultConsulService
+		}
+		if settings.WaitTime <= 0 {
+			settings.WaitTime = defaultConsulWaitTime
+		}
+		if settings.RetryInterval <= 0 {
+			settings.RetryInterval = defaultConsulRetryInterval
+		}
+		if settings.MinInterval <= 0 {
+			settings.MinInterval = defaultConsulMinInterval
+		}
+		if client == nil {
+			client = &http.Client{}
+		}
+		watcher := &consulWatcher{client: client, settings: settings}
+		d := &discovery{
+			name:    "Consul service " + settings.Service,
+			resolve: watcher.resolve,
+			onError: settings.OnError,
+		}
+		if settings.Watch {
+			d.blocking = true
+			d.interval = settings.RetryInterval
+			d.minInterval = settings.MinInterval
+		}
+		c.addDiscovery(d)
+		return nil
+	}
+}
+
+// consulWatcher queries the healthy instances of a service. Once the instances were queried, the next query blocks
+// until the instances change.
+type consulWatcher struct {
+	client   *http.Client
+	settings ConsulSettings
+	mu       sync.Mutex
+	index    uint64
+}
+
+func (w *consulWatcher) resolve(ctx context.Context) ([]*HTTPClient, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	params := map[string]string{"passing": "true"}
+	if w.settings.Tag != "" {
+		params["tag"] = w.settings.Tag
+	}
+	if w.settings.Datacenter != "" {
+		params["dc"] = w.settings.Datacenter
+	}
+	if w.settings.Watch && w.index > 0 {
+		params["index"] = strconv.FormatUint(w.index, 10)
+		params["wait"] = w.settings.WaitTime.String()
+	}
+	entries, index, err := w.query(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	// the index is reset if it goes backwards, e.g. after the Consul servers restarted, and is at least 1 so the next
+	// query blocks even if Consul did not respond with an index
+	if index < w.index {
+		index = 0
+	}
+	w.index = max(index, 1)
+	servers := make([]*HTTPClient, len(entries))
+	for i, entry := range entries {
+		servers[i] = &HTTPClient{BaseURL: entry.baseURL(), Client: w.client}
+	}
+	return servers, nil
+}
+
+func (w *consulWatcher) query(ctx context.Context, params map[string]string) (entries []consulEntry, index uint64, err error) {
+	healthURL, err := newURL(w.settings.Address, []string{"v1", "health", "service", w.settings.Service}, params)
+	if err != nil {
+		return nil, 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request for %s: %w", redactURL(healthURL), err)
+	}
+	if w.settings.Token != "" {
+		req.Header.Set("X-Consul-Token", w.settings.Token)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to retrieve from %s: %w", redactURL(healthURL), err)
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to close body: %w", cerr))
+		}
+	}()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read body with status code '%d': %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, newServerError(healthURL, resp, b)
+	}
+	if err = json.Unmarshal(b, &entries); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode response from %s: %w", redactURL(healthURL), err)
+	}
+	index, _ = strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	return entries, index, nil
+}
+
+// consulEntry is an entry of Consul's health API.
+type consulEntry struct {
+	Node struct {
+		Address string `json:"Address"`
+	} `json:"Node"`
+	Service struct {
+		Address string            `json:"Address"`
+		Port    int               `json:"Port"`
+		Tags    []string          `json:"Tags"`
+		Meta    map[string]string `json:"Meta"`
+	} `json:"Service"`
+}
+
+// baseURL returns the URL of the instance. The address of the service falls back to the address of its node.
+func (e consulEntry) baseURL() string {
+	address := e.Service.Address
+	if address == "" {
+		address = e.Node.Address
+	}
+	scheme := "http"
+	if e.value("secure") == "true" {
+		scheme = "https"
+	}
+	baseURL := (&url.URL{Scheme: scheme, Host: net.JoinHostPort(address, strconv.Itoa(e.Service.Port))}).String()
+	if configPath := strings.Trim(e.value("configPath"), "/"); configPath != "" {
+		baseURL += "/" + configPath
+	}
+	return baseURL
+}
+
+// value returns the metadata of the service with the key, or the value of its 'key=value' tag.
+func (e consulEntry) value(key string) string {
+	if value, ok := e.Service.Meta[key]; ok {
+		return value
+	}
+	for _, tag := range e.Service.Tags {
+		if k, value, ok := strings.Cut(tag, "="); ok && k == key {
+			return value
+		}
+	}
+	return ""
+}
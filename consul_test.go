@@ -0,0 +1,158 @@
+package cloudconfigclient_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Piszmog/cloudconfigclient/v3"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConsul is a Consul agent that responds with the entries it is set with. A blocking query waits until the
+// entries change.
+type fakeConsul struct {
+	*httptest.Server
+	mu      sync.Mutex
+	index   int
+	entries string
+	changed chan struct{}
+	queries []string
+}
+
+func newFakeConsul(t *testing.T, entries string) *fakeConsul {
+	consul := &fakeConsul{index: 1, entries: entries, changed: make(chan struct{})}
+	consul.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/health/service/configserver", r.URL.Path)
+		consul.mu.Lock()
+		consul.queries = append(consul.queries, r.URL.RawQuery)
+		changed := consul.changed
+		blocking := r.URL.Query().Get("index") == strconv.Itoa(consul.index)
+		consul.mu.Unlock()
+		if blocking {
+			wait, err := time.ParseDuration(r.URL.Query().Get("wait"))
+			require.NoError(t, err)
+			select {
+			case <-changed:
+			case <-time.After(wait):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		consul.mu.Lock()
+		defer consul.mu.Unlock()
+		w.Header().Set("X-Consul-Index", strconv.Itoa(consul.index))
+		_, _ = w.Write([]byte(consul.entries))
+	}))
+	t.Cleanup(consul.Close)
+	return consul
+}
+
+func (f *fakeConsul) set(entries string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.index++
+	f.entries = entries
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func consulEntry(address string, port int, tags []string, meta map[string]string) map[string]any {
+	return map[string]any{
+		"Node":    map[string]any{"Address": "10.0.0.1"},
+		"Service": map[string]any{"Address": address, "Port": port, "Tags": tags, "Meta": meta},
+	}
+}
+
+func consulEntries(t *testing.T, entries ...map[string]any) string {
+	b, err := json.Marshal(entries)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestConsul(t *testing.T) {
+	consul := newFakeConsul(t, consulEntries(t,
+		consulEntry("config1", 8888, nil, nil),
+		consulEntry("", 8888, []string{"secure=true", "configPath=/config"}, nil),
+		consulEntry("config3", 8443, nil, map[string]string{"secure": "true"}),
+	))
+	client, err := cloudconfigclient.New(cloudconfigclient.Consul(&http.Client{}, cloudconfigclient.ConsulSettings{
+		Address:    consul.URL,
+		Tag:        "primary",
+		Datacenter: "dc1",
+	}))
+	require.NoError(t, err)
+	defer client.Close()
+
+	require.Equal(t, []string{"http://config1:8888", "https://10.0.0.1:8888/config", "https://config3:8443"}, baseURLs(client))
+	require.Equal(t, []string{"dc=dc1&passing=true&tag=primary"}, consul.queries)
+}
+
+func TestConsul_Watch(t *testing.T) {
+	consul := newFakeConsul(t, consulEntries(t, consulEntry("config1", 8888, nil, nil)))
+	client, err := cloudconfigclient.New(cloudconfigclient.Consul(&http.Client{}, cloudconfigclient.ConsulSettings{
+		Address:  consul.URL,
+		Watch:    true,
+		WaitTime: time.Minute,
+	}))
+	require.NoError(t, err)
+	defer client.Close()
+	require.Equal(t, []string{"http://config1:8888"}, baseURLs(client))
+
+	// the blocking query returns as soon as the membership changes
+	require.Eventually(t, func() bool {
+		consul.mu.Lock()
+		defer consul.mu.Unlock()
+		return len(consul.queries) == 2
+	}, time.Second, 5*time.Millisecond)
+	consul.set(consulEntries(t, consulEntry("config1", 8888, nil, nil), consulEntry("config2", 8888, nil, nil)))
+	require.Eventually(t, func() bool {
+		return len(baseURLs(client)) == 2
+	}, time.Second, 5*time.Millisecond)
+	require.Equal(t, []string{"http://config1:8888", "http://config2:8888"}, baseURLs(client))
+
+	consul.mu.Lock()
+	require.Equal(t, "passing=true", consul.queries[0])
+	require.Equal(t, "index=1&passing=true&wait=1m0s", consul.queries[1])
+	consul.mu.Unlock()
+}
+
+func TestConsul_Error(t *testing.T) {
+	consul := newFakeConsul(t, "[]")
+	_, err := cloudconfigclient.New(cloudconfigclient.Consul(&http.Client{}, cloudconfigclient.ConsulSettings{Address: consul.URL}))
+	require.EqualError(t, err, "failed to discover Config Servers from Consul service configserver: no Config Servers found")
+}
+
+func TestConsul_WatchWithoutIndex(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.RawQuery)
+		mu.Unlock()
+		// the agent responds immediately and without an X-Consul-Index header
+		_, _ = w.Write([]byte(consulEntries(t, consulEntry("config1", 8888, nil, nil))))
+	}))
+	defer server.Close()
+	client, err := cloudconfigclient.New(cloudconfigclient.Consul(&http.Client{}, cloudconfigclient.ConsulSettings{
+		Address:     server.URL,
+		Watch:       true,
+		WaitTime:    time.Minute,
+		MinInterval: 100 * time.Millisecond,
+	}))
+	require.NoError(t, err)
+	time.Sleep(350 * time.Millisecond)
+	require.NoError(t, client.Close())
+
+	mu.Lock()
+	defer mu.Unlock()
+	// the queries are spread out by the minimum interval instead of flooding the agent
+	require.GreaterOrEqual(t, len(queries), 3)
+	require.LessOrEqual(t, len(queries), 6)
+	require.Equal(t, "passing=true", queries[0])
+	require.Equal(t, "index=1&passing=true&wait=1m0s", queries[1])
+}
@@ -0,0 +1,130 @@
+package cloudconfigclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialsProvider provides the username and password used to authorize requests to a Config Server with basic
+// authentication. It is consulted before every request, so rotated credentials are picked up without restarting.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (username string, password string, err error)
+}
+
+// CredentialsFunc is an adapter to use an ordinary function as a CredentialsProvider.
+type CredentialsFunc func(ctx context.Context) (username string, password string, err error)
+
+// Credentials calls f(ctx).
+func (f CredentialsFunc) Credentials(ctx context.Context) (string, string, error) {
+	return f(ctx)
+}
+
+// BasicWithProvider is like Basic but retrieves the username and password from the provider before every request. If
+// a Config Server responds with a 401, the request is sent once more with fresh credentials.
+func BasicWithProvider(client *http.Client, provider CredentialsProvider, urls ...string) Option {
+	return func(c *Client) error {
+		if provider == nil {
+			return errors.New("credentials provider must not be nil")
+		}
+		auth := &basicAuthorizer{provider: provider}
+		for _, baseURL := range urls {
+			c.clients = append(c.clients, &HTTPClient{BaseURL: baseURL, Client: client, auth: auth})
+		}
+		return nil
+	}
+}
+
+// basicAuthorizer authorizes requests with the basic authentication credentials of the provider.
+type basicAuthorizer struct {
+	provider CredentialsProvider
+}
+
+func (b *basicAuthorizer) authorize(ctx context.Context, req *http.Request) error {
+	username, password, err := b.provider.Credentials(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve credentials: %w", err)
+	}
+	req.SetBasicAuth(username, password)
+	return nil
+}
+
+func (b *basicAuthorizer) scheme() string {
+	return "Basic"
+}
+
+func (b *basicAuthorizer) invalidate() {
+	if provider, ok := b.provider.(invalidator); ok {
+		provider.invalidate()
+	}
+}
+
+// FileCredentials returns a CredentialsProvider that reads the username and password from files, e.g. a mounted
+// Kubernetes secret. The files are read again whenever they change. Trailing line breaks are ignored.
+func FileCredentials(usernameFile string, passwordFile string) CredentialsProvider {
+	return &fileCredentials{usernameFile: usernameFile, passwordFile: passwordFile}
+}
+
+type fileCredentials struct {
+	usernameFile string
+	passwordFile string
+	mu           sync.Mutex
+	username     credentialFile
+	password     credentialFile
+}
+
+// credentialFile is the content of a credential file and the version of the file it was read from.
+type credentialFile struct {
+	value   string
+	modTime time.Time
+	size    int64
+	read    bool
+}
+
+func (f *fileCredentials) Credentials(ctx context.Context) (string, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.username.load(f.usernameFile); err != nil {
+		return "", "", err
+	}
+	if err := f.password.load(f.passwordFile); err != nil {
+		return "", "", err
+	}
+	return f.username.value, f.password.value, nil
+}
+
+// invalidate reads the files again on the next request, even if they did not appear to change. The modification time
+// of a file may not change if it was rewritten within the resolution of the file system's clock.
+func (f *fileCredentials) invalidate() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.username.read = false
+	f.password.read = false
+}
+
+// load reads the file if it changed since it was last read.
+func (c *credentialFile) load(name string) error {
+	info, err := os.Stat(name)
+	if err != nil {
+		return fmt.Errorf("failed to read credentials file: %w", err)
+	}
+	if c.read && info.ModTime().Equal(c.modTime) && info.Size() == c.size {
+		return nil
+	}
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("failed to read credentials file: %w", err)
+	}
+	*c = credentialFile{
+		value:   strings.TrimRight(string(b), "\r\n"),
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		read:    true,
+	}
+	return nil
+}
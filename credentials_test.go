@@ -0,0 +1,129 @@
+package cloudconfigclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Piszmog/cloudconfigclient/v3"
+	"github.com/stretchr/testify/require"
+)
+
+// newBasicAuthClient creates a mocked HTTP Client that responds with a 401 unless the request has the credentials.
+func newBasicAuthClient(username, password *string, requests *int) *http.Client {
+	return NewMockHttpClient(func(req *http.Request) *http.Response {
+		*requests++
+		u, p, ok := req.BasicAuth()
+		if !ok || u != *username || p != *password {
+			return NewMockHttpResponse(http.StatusUnauthorized, "")
+		}
+		return NewMockHttpResponse(http.StatusOK, configurationSource)
+	})
+}
+
+func TestFileCredentials(t *testing.T) {
+	dir := t.TempDir()
+	usernameFile := filepath.Join(dir, "username")
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(usernameFile, []byte("user\n"), 0o600))
+	require.NoError(t, os.WriteFile(passwordFile, []byte("secret1\n"), 0o600))
+
+	username, password, requests := "user", "secret1", 0
+	client, err := cloudconfigclient.New(cloudconfigclient.BasicWithProvider(
+		newBasicAuthClient(&username, &password, &requests),
+		cloudconfigclient.FileCredentials(usernameFile, passwordFile),
+		"http://localhost:8888",
+	))
+	require.NoError(t, err)
+	_, err = client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+	require.Equal(t, 1, requests)
+
+	// the secret is rotated
+	password = "secret2"
+	require.NoError(t, os.WriteFile(passwordFile, []byte("secret2\n"), 0o600))
+	_, err = client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+	require.LessOrEqual(t, requests, 3)
+}
+
+func TestFileCredentials_MissingFile(t *testing.T) {
+	client, err := cloudconfigclient.New(cloudconfigclient.BasicWithProvider(
+		&http.Client{},
+		cloudconfigclient.FileCredentials(filepath.Join(t.TempDir(), "username"), filepath.Join(t.TempDir(), "password")),
+		"http://localhost:8888",
+	))
+	require.NoError(t, err)
+	_, err = client.GetConfiguration("appName", "profile")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestBasicWithProvider(t *testing.T) {
+	tests := []struct {
+		name      string
+		passwords []string
+		requests  int
+		err       string
+	}{
+		{
+			name:      "Credentials Valid",
+			passwords: []string{"secret"},
+			requests:  1,
+		},
+		{
+			name:      "Credentials Rotated",
+			passwords: []string{"old", "secret"},
+			requests:  2,
+		},
+		{
+			name:      "Credentials Invalid",
+			passwords: []string{"old", "older"},
+			requests:  2,
+			err:       "server responded with status code '401' and body ''",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			username, password, requests := "user", "secret", 0
+			calls := 0
+			provider := cloudconfigclient.CredentialsFunc(func(ctx context.Context) (string, string, error) {
+				p := test.passwords[min(calls, len(test.passwords)-1)]
+				calls++
+				return "user", p, nil
+			})
+			client, err := cloudconfigclient.New(cloudconfigclient.BasicWithProvider(
+				newBasicAuthClient(&username, &password, &requests),
+				provider,
+				"http://localhost:8888",
+			))
+			require.NoError(t, err)
+
+			_, err = client.GetConfiguration("appName", "profile")
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, test.requests, requests)
+		})
+	}
+}
+
+func TestBasicWithProvider_Error(t *testing.T) {
+	_, err := cloudconfigclient.New(cloudconfigclient.BasicWithProvider(&http.Client{}, nil, "http://localhost:8888"))
+	require.EqualError(t, err, "credentials provider must not be nil")
+
+	client, err := cloudconfigclient.New(cloudconfigclient.BasicWithProvider(
+		&http.Client{},
+		cloudconfigclient.CredentialsFunc(func(ctx context.Context) (string, string, error) {
+			return "", "", errors.New("secret store unavailable")
+		}),
+		"http://localhost:8888",
+	))
+	require.NoError(t, err)
+	_, err = client.GetConfiguration("appName", "profile")
+	require.EqualError(t, err, "failed to authorize request for http://localhost:8888/appName/profile: failed to retrieve credentials: secret store unavailable")
+}
@@ -0,0 +1,130 @@
+package cloudconfigclient
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// discovery resolves the Config Servers of a discovery service, e.g. DNS SRV records.
+type discovery struct {
+	// name identifies the discovery service in errors.
+	name string
+	// resolve returns the HTTPClients of the Config Servers currently registered with the discovery service.
+	resolve func(ctx context.Context) ([]*HTTPClient, error)
+	// interval is how often the Config Servers are resolved again. If zero, they are resolved once.
+	interval time.Duration
+	// blocking is whether resolve blocks until the Config Servers change, e.g. a Consul blocking query. If so, the
+	// Config Servers are resolved again as soon as resolve returns, and interval is the wait after a failure.
+	blocking bool
+	// minInterval is the minimum duration between the starts of blocking resolves, so the discovery service is not
+	// flooded when resolve returns immediately.
+	minInterval time.Duration
+	// onError is called with the errors of resolving the Config Servers again. May be nil.
+	onError func(err error)
+}
+
+// addDiscovery registers the discovery service with the Client. Its Config Servers are resolved once the options
+// are applied.
+func (c *Client) addDiscovery(d *discovery) {
+	c.discoveries = append(c.discoveries, d)
+	c.discovered = append(c.discovered, nil)
+}
+
+// startDiscovery resolves the Config Servers of each discovery service and starts resolving them again periodically.
+func (c *Client) startDiscovery() error {
+	if len(c.discoveries) == 0 {
+		return nil
+	}
+	for i := range c.discoveries {
+		if err := c.refresh(context.Background(), i); err != nil {
+			return err
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stopRefresh = cancel
+	for i, d := range c.discoveries {
+		if d.interval <= 0 && !d.blocking {
+			continue
+		}
+		c.refreshes.Add(1)
+		go func() {
+			defer c.refreshes.Done()
+			c.watch(ctx, i, d)
+		}()
+	}
+	return nil
+}
+
+// watch resolves the Config Servers of the discovery service every interval until the Client is closed. If the
+// Config Servers cannot be resolved, the previously resolved Config Servers are kept.
+func (c *Client) watch(ctx context.Context, index int, d *discovery) {
+	if d.blocking {
+		for ctx.Err() == nil {
+			start := time.Now()
+			if err := c.refresh(ctx, index); err != nil && ctx.Err() == nil {
+				if d.onError != nil {
+					d.onError(err)
+				}
+				_ = sleep(ctx, d.interval)
+				continue
+			}
+			if wait := d.minInterval - time.Since(start); wait > 0 {
+				_ = sleep(ctx, wait)
+			}
+		}
+		return
+	}
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.refresh(ctx, index); err != nil && ctx.Err() == nil && d.onError != nil {
+				d.onError(err)
+			}
+		}
+	}
+}
+
+// refresh resolves the Config Servers of the discovery service and replaces the previously resolved ones. Errors are
+// logged, unless the context was cancelled.
+func (c *Client) refresh(ctx context.Context, index int) (err error) {
+	d := c.discoveries[index]
+	defer func() {
+		if err != nil && ctx.Err() == nil {
+			c.log().WarnContext(ctx, "failed to discover Config Servers", slog.String("source", d.name), slog.Any("error", err))
+		}
+	}()
+	servers, err := d.resolve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to discover Config Servers from %s: %w", d.name, err)
+	}
+	if len(servers) == 0 {
+		return fmt.Errorf("failed to discover Config Servers from %s: no Config Servers found", d.name)
+	}
+	c.log().DebugContext(ctx, "discovered Config Servers", slog.String("source", d.name), slog.Int("count", len(servers)))
+	c.serversMu.Lock()
+	defer c.serversMu.Unlock()
+	for _, server := range servers {
+		if err = c.prepare(server); err != nil {
+			return err
+		}
+	}
+	c.discovered[index] = servers
+	return nil
+}
+
+// Close stops resolving the Config Servers of the discovery services again. The Client can still be used afterward,
+// with the Config Servers resolved last. Close is only required for Clients created with a discovery Option that
+// refreshes periodically.
+func (c *Client) Close() error {
+	if c.stopRefresh != nil {
+		c.stopRefresh()
+		c.refreshes.Wait()
+	}
+	return nil
+}
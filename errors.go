@@ -0,0 +1,101 @@
+package cloudconfigclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ServerError is returned when a Config Server responds with an unexpected status code. Use errors.As to inspect it.
+//
+// A 404 is not a ServerError, ErrResourceNotFound is returned instead.
+type ServerError struct {
+	// StatusCode is the status code of the response.
+	StatusCode int
+	// URL is the URL of the request with any credentials redacted.
+	URL string
+	// ServerIndex is the index of the Config Server in the order the Config Servers were provided to the Client. It
+	// is -1 when the HTTPClient was used directly.
+	ServerIndex int
+	// Header is the header of the response.
+	Header http.Header
+	// Body is the body of the response.
+	Body []byte
+	// Response is the Spring Boot error the Config Server responded with. Nil if the body is not a Spring Boot error.
+	Response *ErrorResponse
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("server responded with status code '%d' and body '%s'", e.StatusCode, e.Body)
+}
+
+// ErrorResponse is the JSON body of a Spring Boot error response.
+type ErrorResponse struct {
+	// Timestamp is when the error occurred.
+	Timestamp time.Time
+	// Status is the status code of the response.
+	Status int
+	// Error is the reason phrase of the status code, e.g. 'Internal Server Error'.
+	Error string
+	// Message describes the error.
+	Message string
+	// Path is the path of the request.
+	Path string
+}
+
+// UnmarshalJSON converts a Spring Boot error response to an ErrorResponse. The timestamp may either be formatted as
+// RFC 3339 or be the milliseconds since epoch, depending on the version of Spring Boot.
+func (e *ErrorResponse) UnmarshalJSON(b []byte) error {
+	var body struct {
+		Timestamp json.RawMessage `json:"timestamp"`
+		Status    int             `json:"status"`
+		Error     string          `json:"error"`
+		Message   string          `json:"message"`
+		Path      string          `json:"path"`
+	}
+	if err := json.Unmarshal(b, &body); err != nil {
+		return err
+	}
+	*e = ErrorResponse{Status: body.Status, Error: body.Error, Message: body.Message, Path: body.Path}
+	if len(body.Timestamp) == 0 {
+		return nil
+	}
+	if millis, err := strconv.ParseInt(string(body.Timestamp), 10, 64); err == nil {
+		e.Timestamp = time.UnixMilli(millis).UTC()
+		return nil
+	}
+	var timestamp string
+	if err := json.Unmarshal(body.Timestamp, &timestamp); err != nil {
+		return fmt.Errorf("failed to parse timestamp: %w", err)
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		// Spring Boot formats the offset without a colon in some versions, e.g. 2006-01-02T15:04:05.000+0000
+		parsed, err = time.Parse("2006-01-02T15:04:05.000-0700", timestamp)
+		if err != nil {
+			return fmt.Errorf("failed to parse timestamp: %w", err)
+		}
+	}
+	e.Timestamp = parsed
+	return nil
+}
+
+func newServerError(requestURL string, resp *http.Response, body []byte) *ServerError {
+	serverErr := &ServerError{
+		StatusCode:  resp.StatusCode,
+		URL:         redactURL(requestURL),
+		ServerIndex: -1,
+		Header:      resp.Header,
+		Body:        body,
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+		var errResp ErrorResponse
+		if err := json.Unmarshal(body, &errResp); err == nil && (errResp.Status != 0 || errResp.Error != "") {
+			serverErr.Response = &errResp
+		}
+	}
+	return serverErr
+}
@@ -0,0 +1,192 @@
+package cloudconfigclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultEurekaServiceID is the service ID the Config Server registers with by default, like
+// spring.cloud.config.discovery.service-id.
+const defaultEurekaServiceID = "configserver"
+
+// EurekaSettings configures the discovery of Config Servers from a Eureka registry.
+type EurekaSettings struct {
+	// URLs are the service URLs of the Eureka servers, e.g. 'http://eureka:8761/eureka'. They are tried in order.
+	URLs []string
+	// ServiceID is the service ID the Config Servers are registered with. Defaults to 'configserver'.
+	ServiceID string
+	// RefreshInterval is how often the instances are queried again. If zero, they are queried once.
+	RefreshInterval time.Duration
+	// OnError is called when querying the instances again fails. The previously found Config Servers are kept.
+	OnError func(err error)
+}
+
+// Eureka discovers the Config Servers from a Eureka registry, like spring.cloud.config.discovery. The client is used
+// for the requests to the Eureka servers and the Config Servers.
+//
+// Only instances with the status UP are used. The URL of a Config Server is the home page URL of its instance, with
+// the 'configPath' metadata appended. If the instance has 'user' and 'password' metadata, requests are authorized
+// with basic authentication.
+//
+// If a RefreshInterval is set, the Client must be closed to stop querying the instances.
+func Eureka(client *http.Client, settings EurekaSettings) Option {
+	return func(c *Client) error {
+		if len(settings.URLs) == 0 {
+			return errors.New("at least one Eureka URL must be provided")
+		}
+		if settings.ServiceID == "" {
+			settings.ServiceID = defaultEurekaServiceID
+		}
+		if client == nil {
+			client = &http.Client{}
+		}
+		c.addDiscovery(&discovery{
+			name: "Eureka service " + settings.ServiceID,
+			resolve: func(ctx context.Context) ([]*HTTPClient, error) {
+				instances, err := eurekaInstances(ctx, client, settings)
+				if err != nil {
+					return nil, err
+				}
+				var servers []*HTTPClient
+				for _, instance := range instances {
+					if instance.Status != "UP" {
+						continue
+					}
+					server := &HTTPClient{BaseURL: instance.baseURL(), Client: client}
+					if user := instance.Metadata["user"]; user != "" {
+						server.Authorization = basicAuthorization(user, instance.Metadata["password"])
+					}
+					servers = append(servers, server)
+				}
+				return servers, nil
+			},
+			interval: settings.RefreshInterval,
+			onError:  settings.OnError,
+		})
+		return nil
+	}
+}
+
+// eurekaInstances queries the instances of the service from the first Eureka server that responds.
+func eurekaInstances(ctx context.Context, client *http.Client, settings EurekaSettings) ([]eurekaInstance, error) {
+	var errs []error
+	for _, eurekaURL := range settings.URLs {
+		instances, err := queryEureka(ctx, client, eurekaURL, settings.ServiceID)
+		if err == nil {
+			return instances, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
+
+func queryEureka(ctx context.Context, client *http.Client, eurekaURL string, serviceID string) (instances []eurekaInstance, err error) {
+	appURL, err := newURL(eurekaURL, []string{"apps", serviceID}, nil)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, appURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", redactURL(appURL), err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve from %s: %w", redactURL(appURL), err)
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to close body: %w", cerr))
+		}
+	}()
+	if resp.StatusCode == http.StatusNotFound {
+		// Eureka responds with a 404 when no instance of the service is registered
+		return nil, nil
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body with status code '%d': %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newServerError(appURL, resp, b)
+	}
+	var body struct {
+		Application struct {
+			Instance json.RawMessage `json:"instance"`
+		} `json:"application"`
+	}
+	if err = json.Unmarshal(b, &body); err != nil {
+		return nil, fmt.Errorf("failed to decode response from %s: %w", redactURL(appURL), err)
+	}
+	instance := bytes.TrimSpace(body.Application.Instance)
+	if len(instance) == 0 {
+		return nil, nil
+	}
+	// Eureka responds with an object instead of an array when a single instance is registered
+	if instance[0] == '{' {
+		instance = append(append([]byte("["), instance...), ']')
+	}
+	if err = json.Unmarshal(instance, &instances); err != nil {
+		return nil, fmt.Errorf("failed to decode response from %s: %w", redactURL(appURL), err)
+	}
+	return instances, nil
+}
+
+// eurekaInstance is an instance registered with Eureka.
+type eurekaInstance struct {
+	HostName    string            `json:"hostName"`
+	Status      string            `json:"status"`
+	HomePageURL string            `json:"homePageUrl"`
+	Port        eurekaPort        `json:"port"`
+	SecurePort  eurekaPort        `json:"securePort"`
+	Metadata    map[string]string `json:"metadata"`
+}
+
+// eurekaPort is a port of an instance, e.g. {"$": 8080, "@enabled": "true"}.
+type eurekaPort struct {
+	Port    string
+	Enabled string
+}
+
+func (p *eurekaPort) UnmarshalJSON(b []byte) error {
+	var port struct {
+		Port    json.RawMessage `json:"$"`
+		Enabled any             `json:"@enabled"`
+	}
+	if err := json.Unmarshal(b, &port); err != nil {
+		return err
+	}
+	// depending on the version of Eureka, the port and enabled flag are either strings or literals
+	p.Port = strings.Trim(string(port.Port), `"`)
+	p.Enabled = fmt.Sprint(port.Enabled)
+	return nil
+}
+
+// baseURL returns the home page URL of the instance, or its host and port if it has none, with the configPath
+// metadata appended.
+func (i eurekaInstance) baseURL() string {
+	baseURL := strings.TrimSuffix(i.HomePageURL, "/")
+	if baseURL == "" {
+		scheme, port := "http", i.Port.Port
+		if i.SecurePort.Enabled == "true" {
+			scheme, port = "https", i.SecurePort.Port
+		}
+		baseURL = (&url.URL{Scheme: scheme, Host: net.JoinHostPort(i.HostName, port)}).String()
+	}
+	if configPath := strings.Trim(i.Metadata["configPath"], "/"); configPath != "" {
+		baseURL += "/" + configPath
+	}
+	return baseURL
+}
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/Piszmog/cloudconfigclient/v2"
+	"github.com/Piszmog/cloudconfigclient/v3"
 )
 
 func main() {
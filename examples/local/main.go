@@ -5,7 +5,7 @@ import (
 	"log"
 	"net/http"
 
-	"github.com/Piszmog/cloudconfigclient/v2"
+	"github.com/Piszmog/cloudconfigclient/v3"
 )
 
 func main() {
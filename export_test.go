@@ -0,0 +1,11 @@
+package cloudconfigclient
+
+import "time"
+
+// NewOAuth2Client exposes newOAuth2Client for the external test package.
+var NewOAuth2Client = newOAuth2Client
+
+// Backoff exposes backoff for the external test package.
+func (p *RetryPolicy) Backoff(retry int) time.Duration {
+	return p.backoff(retry)
+}
@@ -0,0 +1,247 @@
+package cloudconfigclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FailoverPolicy configures which failures of a Config Server cause the Client to try the next Config Server.
+//
+// A Config Server responding with a 404 always causes the next Config Server to be tried.
+type FailoverPolicy struct {
+	// StatusCodes are the response status codes that cause the next Config Server to be tried.
+	StatusCodes []int
+	// TransportErrors causes the next Config Server to be tried when a request could not be sent or no response was
+	// received, e.g. the connection was refused.
+	TransportErrors bool
+}
+
+// DefaultFailoverPolicy returns a FailoverPolicy that tries the next Config Server on transport errors and on the
+// status codes 500, 502, 503 and 504.
+func DefaultFailoverPolicy() FailoverPolicy {
+	return FailoverPolicy{
+		StatusCodes: []int{
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		TransportErrors: true,
+	}
+}
+
+// WithFailover sets the FailoverPolicy of the Client. Without it, the DefaultFailoverPolicy is used. An empty
+// FailoverPolicy only tries the next Config Server on a 404.
+func WithFailover(policy FailoverPolicy) Option {
+	return func(c *Client) error {
+		c.failover = policy
+		return nil
+	}
+}
+
+func (p FailoverPolicy) shouldFailover(err error) bool {
+	if errors.Is(err, ErrResourceNotFound) {
+		return true
+	}
+	var serverErr *ServerError
+	if errors.As(err, &serverErr) {
+		return slices.Contains(p.StatusCodes, serverErr.StatusCode)
+	}
+	var urlErr *url.Error
+	return p.TransportErrors && errors.As(err, &urlErr)
+}
+
+// FailoverError is returned when every Config Server failed to serve a request and at least one of them failed with
+// an error other than a 404.
+type FailoverError struct {
+	// Errors contains the failure of each Config Server, in the order the Config Servers were tried.
+	Errors []error
+}
+
+func (e *FailoverError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return "failed to retrieve from every Config Server: " + strings.Join(messages, "; ")
+}
+
+func (e *FailoverError) Unwrap() []error {
+	return e.Errors
+}
+
+// WithHedging enables hedged requests. If a Config Server has not responded within the delay, the request is also sent
+// to the next Config Server. The first successful response is used and the remaining requests are cancelled.
+//
+// Hedging trades additional load on the Config Servers for lower latency, e.g. during startup.
+func WithHedging(delay time.Duration) Option {
+	return func(c *Client) error {
+		if delay <= 0 {
+			return errors.New("hedging delay must be greater than zero")
+		}
+		c.hedgeDelay = delay
+		return nil
+	}
+}
+
+type attemptResult[T any] struct {
+	client   *HTTPClient
+	value    T
+	err      error
+	duration time.Duration
+}
+
+// fetch calls fn with the HTTPClient of each Config Server until one of them succeeds. The next Config Server is only
+// tried when the FailoverPolicy allows it, otherwise the error is returned as is, like the error of a single Config
+// Server. Once the context is done, the
+// remaining Config Servers are not tried. Config Servers with an open circuit breaker are skipped.
+//
+// If hedging is enabled, the next Config Server is also tried when the in-flight requests have not completed within
+// the hedging delay. The context passed to fn is cancelled once a result has been chosen.
+//
+// If every Config Server responded with a 404, found is false and no error is returned. The outcome of each Config
+// Server is logged with the logger.
+func fetch[T any](ctx context.Context, c *Client, logger *slog.Logger, fn func(ctx context.Context, client *HTTPClient) (T, error)) (value T, found bool, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	all := c.servers()
+	servers := c.order(all)
+	results := make(chan attemptResult[T], len(servers))
+	var errs []error
+	notFound := true
+	next, inFlight := 0, 0
+	// launch sends the request to the next Config Server that is allowed by its circuit breaker
+	launch := func() bool {
+		for next < len(servers) && ctx.Err() == nil {
+			client := servers[next]
+			next++
+			breaker := c.breaker(client)
+			if breaker != nil && !breaker.allow() {
+				notFound = false
+				errs = append(errs, fmt.Errorf("%s: %w", redactURL(client.BaseURL), ErrCircuitOpen))
+				logger.WarnContext(ctx, "skipping Config Server with open circuit breaker",
+					slog.String("server", redactURL(client.BaseURL)),
+				)
+				continue
+			}
+			inFlight++
+			go func() {
+				start := time.Now()
+				v, attemptErr := fn(ctx, client)
+				elapsed := time.Since(start)
+				var serverErr *ServerError
+				if errors.As(attemptErr, &serverErr) {
+					serverErr.ServerIndex = slices.Index(all, client)
+				}
+				c.record(ctx, client, breaker, attemptErr, elapsed)
+				results <- attemptResult[T]{client: client, value: v, err: attemptErr, duration: elapsed}
+			}()
+			return true
+		}
+		return false
+	}
+
+	var hedge *time.Timer
+	if c.hedgeDelay > 0 {
+		hedge = time.NewTimer(c.hedgeDelay)
+		defer hedge.Stop()
+	}
+	launch()
+	for inFlight > 0 {
+		var hedgeC <-chan time.Time
+		if hedge != nil && next < len(servers) {
+			hedgeC = hedge.C
+		}
+		select {
+		case <-ctx.Done():
+			return value, false, ctx.Err()
+		case <-hedgeC:
+			logger.DebugContext(ctx, "Config Server has not responded within the hedging delay, hedging the request",
+				slog.Duration("delay", c.hedgeDelay),
+			)
+			if launch() {
+				hedge.Reset(c.hedgeDelay)
+			}
+		case result := <-results:
+			inFlight--
+			attrs := []slog.Attr{
+				slog.String("server", redactURL(result.client.BaseURL)),
+				slog.Duration("duration", result.duration),
+			}
+			if result.err == nil {
+				logger.LogAttrs(ctx, slog.LevelDebug, "retrieved from Config Server", attrs...)
+				return result.value, true, nil
+			}
+			if ctx.Err() != nil {
+				return value, false, ctx.Err()
+			}
+			attrs = append(attrs, slog.Any("error", result.err))
+			// a single Config Server has no other Config Server to fail over to, so its error is returned as is
+			if !c.failover.shouldFailover(result.err) || (len(servers) == 1 && !errors.Is(result.err, ErrResourceNotFound)) {
+				logger.LogAttrs(ctx, slog.LevelWarn, "failed to retrieve from Config Server", attrs...)
+				return value, false, result.err
+			}
+			if errors.Is(result.err, ErrResourceNotFound) {
+				logger.LogAttrs(ctx, slog.LevelDebug, "Config Server did not find the resource, trying the next Config Server", attrs...)
+			} else {
+				notFound = false
+				logger.LogAttrs(ctx, slog.LevelWarn, "failed to retrieve from Config Server, failing over to the next Config Server", attrs...)
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", redactURL(result.client.BaseURL), result.err))
+			if launch() && hedge != nil {
+				hedge.Reset(c.hedgeDelay)
+			}
+		}
+	}
+	if err = ctx.Err(); err != nil {
+		return value, false, err
+	}
+	if notFound {
+		logger.DebugContext(ctx, "no Config Server found the resource")
+		return value, false, nil
+	}
+	err = &FailoverError{Errors: errs}
+	logger.ErrorContext(ctx, "failed to retrieve from every Config Server", slog.Any("error", err))
+	return value, false, err
+}
+
+// record records the outcome of a request to a Config Server with its circuit breaker and the Strategy. The duration of
+// requests the Config Server responded to is also reported to Strategies that observe latency. Requests that were
+// cancelled, e.g. because another Config Server already responded, are not recorded. Requests that ran out the
+// deadline of the caller are recorded as failures, since the Config Server did not respond in time.
+func (c *Client) record(ctx context.Context, client *HTTPClient, breaker *circuitBreaker, err error, elapsed time.Duration) {
+	if ctx.Err() != nil {
+		if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			if breaker != nil {
+				breaker.release()
+			}
+			return
+		}
+		if !isServerFailure(err) {
+			err = &url.Error{Op: http.MethodGet, URL: redactURL(client.BaseURL), Err: ctx.Err()}
+		}
+	}
+	if breaker != nil {
+		breaker.record(err)
+	}
+	if c.strategy != nil {
+		c.strategy.Report(client, err)
+		if reporter, ok := c.strategy.(latencyReporter); ok && (err == nil || errors.Is(err, ErrResourceNotFound)) {
+			reporter.reportLatency(client, elapsed)
+		}
+	}
+}
+
+func (c *Client) order(servers []*HTTPClient) []*HTTPClient {
+	if c.strategy == nil {
+		return servers
+	}
+	return c.strategy.Order(servers)
+}
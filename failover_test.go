@@ -0,0 +1,306 @@
+package cloudconfigclient_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Piszmog/cloudconfigclient/v3"
+	"github.com/stretchr/testify/require"
+)
+
+// NewMockHostsHttpClient creates a mocked HTTP Client that responds based on the host of the request. A nil response
+// results in a transport error.
+func NewMockHostsHttpClient(requestedHosts *[]string, responses map[string]func() *http.Response) *http.Client {
+	return NewMockHttpClient(func(req *http.Request) *http.Response {
+		*requestedHosts = append(*requestedHosts, req.URL.Host)
+		if response, ok := responses[req.URL.Host]; ok {
+			return response()
+		}
+		return nil
+	})
+}
+
+func TestWithFailover(t *testing.T) {
+	ok := func() *http.Response { return NewMockHttpResponse(http.StatusOK, configurationSource) }
+	notFound := func() *http.Response { return NewMockHttpResponse(http.StatusNotFound, "") }
+	serverError := func() *http.Response { return NewMockHttpResponse(http.StatusInternalServerError, "") }
+	unauthorized := func() *http.Response { return NewMockHttpResponse(http.StatusUnauthorized, "") }
+	tests := []struct {
+		name      string
+		options   []cloudconfigclient.Option
+		responses map[string]func() *http.Response
+		requested []string
+		err       error
+	}{
+		{
+			name:      "No Policy Server Error",
+			responses: map[string]func() *http.Response{"server1": serverError, "server2": ok},
+			requested: []string{"server1", "server2"},
+		},
+		{
+			name:      "No Policy Transport Error",
+			responses: map[string]func() *http.Response{"server2": ok},
+			requested: []string{"server1", "server2"},
+		},
+		{
+			name:      "No Policy Not Found",
+			responses: map[string]func() *http.Response{"server1": notFound, "server2": ok},
+			requested: []string{"server1", "server2"},
+		},
+		{
+			name:      "Empty Policy Server Error",
+			options:   []cloudconfigclient.Option{cloudconfigclient.WithFailover(cloudconfigclient.FailoverPolicy{})},
+			responses: map[string]func() *http.Response{"server1": serverError, "server2": ok},
+			requested: []string{"server1"},
+			err:       errors.New("server responded with status code '500' and body ''"),
+		},
+		{
+			name:      "Empty Policy Not Found",
+			options:   []cloudconfigclient.Option{cloudconfigclient.WithFailover(cloudconfigclient.FailoverPolicy{})},
+			responses: map[string]func() *http.Response{"server1": notFound, "server2": ok},
+			requested: []string{"server1", "server2"},
+		},
+		{
+			name:      "Server Error",
+			options:   []cloudconfigclient.Option{cloudconfigclient.WithFailover(cloudconfigclient.DefaultFailoverPolicy())},
+			responses: map[string]func() *http.Response{"server1": serverError, "server2": ok},
+			requested: []string{"server1", "server2"},
+		},
+		{
+			name:      "Transport Error",
+			options:   []cloudconfigclient.Option{cloudconfigclient.WithFailover(cloudconfigclient.DefaultFailoverPolicy())},
+			responses: map[string]func() *http.Response{"server2": ok},
+			requested: []string{"server1", "server2"},
+		},
+		{
+			name:      "Transport Error Not Allowed",
+			options:   []cloudconfigclient.Option{cloudconfigclient.WithFailover(cloudconfigclient.FailoverPolicy{StatusCodes: []int{http.StatusInternalServerError}})},
+			responses: map[string]func() *http.Response{"server2": ok},
+			requested: []string{"server1"},
+			err:       errors.New("failed to retrieve from http://server1/appName/profile: Get \"http://server1/appName/profile\": http: RoundTripper implementation (cloudconfigclient_test.RoundTripFunc) returned a nil *Response with a nil error"),
+		},
+		{
+			name:      "Status Not Allowed",
+			options:   []cloudconfigclient.Option{cloudconfigclient.WithFailover(cloudconfigclient.DefaultFailoverPolicy())},
+			responses: map[string]func() *http.Response{"server1": unauthorized, "server2": ok},
+			requested: []string{"server1"},
+			err:       errors.New("server responded with status code '401' and body ''"),
+		},
+		{
+			name:      "All Failed",
+			options:   []cloudconfigclient.Option{cloudconfigclient.WithFailover(cloudconfigclient.DefaultFailoverPolicy())},
+			responses: map[string]func() *http.Response{"server1": serverError, "server2": notFound},
+			requested: []string{"server1", "server2"},
+			err:       errors.New("failed to retrieve from every Config Server: http://server1: server responded with status code '500' and body ''; http://server2: failed to find resource"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requested []string
+			httpClient := NewMockHostsHttpClient(&requested, test.responses)
+			options := append([]cloudconfigclient.Option{cloudconfigclient.Local(httpClient, "http://server1", "http://server2")}, test.options...)
+			client, err := cloudconfigclient.New(options...)
+			require.NoError(t, err)
+			_, err = client.GetConfiguration("appName", "profile")
+			if test.err != nil {
+				require.Error(t, err)
+				require.Equal(t, test.err.Error(), err.Error())
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, test.requested, requested)
+		})
+	}
+}
+
+func TestWithFailover_SingleServer(t *testing.T) {
+	var requested []string
+	httpClient := NewMockHostsHttpClient(&requested, map[string]func() *http.Response{
+		"server1": func() *http.Response { return NewMockHttpResponse(http.StatusServiceUnavailable, "") },
+	})
+	client, err := cloudconfigclient.New(cloudconfigclient.Local(httpClient, "http://server1"))
+	require.NoError(t, err)
+
+	// there is no other Config Server to fail over to, so the error is returned as is
+	_, err = client.GetConfiguration("appName", "profile")
+	require.EqualError(t, err, "server responded with status code '503' and body ''")
+	require.Equal(t, []string{"server1"}, requested)
+}
+
+func TestFailoverError(t *testing.T) {
+	var requested []string
+	httpClient := NewMockHostsHttpClient(&requested, map[string]func() *http.Response{
+		"server1": func() *http.Response { return NewMockHttpResponse(http.StatusNotFound, "") },
+	})
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(httpClient, "http://server1", "http://server2"),
+		cloudconfigclient.WithFailover(cloudconfigclient.DefaultFailoverPolicy()),
+	)
+	require.NoError(t, err)
+
+	_, err = client.GetFileRaw("directory", "file.txt")
+	var failoverErr *cloudconfigclient.FailoverError
+	require.ErrorAs(t, err, &failoverErr)
+	require.Len(t, failoverErr.Errors, 2)
+	require.ErrorIs(t, err, cloudconfigclient.ErrResourceNotFound)
+}
+
+func TestWithFailover_FileStopsAtFirstSuccess(t *testing.T) {
+	var requested []string
+	httpClient := NewMockHostsHttpClient(&requested, map[string]func() *http.Response{
+		"server1": func() *http.Response { return NewMockHttpResponse(http.StatusServiceUnavailable, "") },
+		"server2": func() *http.Response { return NewMockHttpResponse(http.StatusOK, testJSONFile) },
+		"server3": func() *http.Response { return NewMockHttpResponse(http.StatusOK, testJSONFile) },
+	})
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(httpClient, "http://server1", "http://server2", "http://server3"),
+		cloudconfigclient.WithFailover(cloudconfigclient.DefaultFailoverPolicy()),
+	)
+	require.NoError(t, err)
+
+	var actual file
+	require.NoError(t, client.GetFile("directory", "file.json", &actual))
+	require.Equal(t, file{Example: example{Field: "value"}}, actual)
+	require.Equal(t, []string{"server1", "server2"}, requested)
+}
+
+// slowTransport responds to each host after its delay. A host without a status responds with a transport error.
+type slowTransport struct {
+	delays    map[string]time.Duration
+	statuses  map[string]int
+	mu        sync.Mutex
+	requested []string
+	cancelled []string
+}
+
+func (s *slowTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	s.requested = append(s.requested, req.URL.Host)
+	s.mu.Unlock()
+	select {
+	case <-time.After(s.delays[req.URL.Host]):
+	case <-req.Context().Done():
+		s.mu.Lock()
+		s.cancelled = append(s.cancelled, req.URL.Host)
+		s.mu.Unlock()
+		return nil, req.Context().Err()
+	}
+	status, ok := s.statuses[req.URL.Host]
+	if !ok {
+		return nil, errors.New("connection refused")
+	}
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(bytes.NewBufferString(`{"name":"` + req.URL.Host + `"}`)),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func (s *slowTransport) calls() ([]string, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requested...), append([]string(nil), s.cancelled...)
+}
+
+func TestWithHedging(t *testing.T) {
+	tests := []struct {
+		name      string
+		delays    map[string]time.Duration
+		statuses  map[string]int
+		expected  string
+		requested []string
+		cancelled []string
+		err       error
+	}{
+		{
+			name:      "First Server Fast",
+			delays:    map[string]time.Duration{"server1": 0, "server2": 0},
+			statuses:  map[string]int{"server1": http.StatusOK, "server2": http.StatusOK},
+			expected:  "server1",
+			requested: []string{"server1"},
+		},
+		{
+			name:      "First Server Slow",
+			delays:    map[string]time.Duration{"server1": 5 * time.Second, "server2": 0},
+			statuses:  map[string]int{"server1": http.StatusOK, "server2": http.StatusOK},
+			expected:  "server2",
+			requested: []string{"server1", "server2"},
+			cancelled: []string{"server1"},
+		},
+		{
+			name:      "Slow Server Fails Over",
+			delays:    map[string]time.Duration{"server1": 5 * time.Second, "server2": 0, "server3": 0},
+			statuses:  map[string]int{"server1": http.StatusOK, "server3": http.StatusOK},
+			expected:  "server3",
+			requested: []string{"server1", "server2", "server3"},
+			cancelled: []string{"server1"},
+		},
+		{
+			name:      "All Fail",
+			delays:    map[string]time.Duration{"server1": 0, "server2": 0, "server3": 0},
+			statuses:  map[string]int{"server1": http.StatusInternalServerError, "server2": http.StatusNotFound},
+			requested: []string{"server1", "server2", "server3"},
+			err:       errors.New("failed to retrieve from every Config Server: http://server1: server responded with status code '500' and body '{\"name\":\"server1\"}'; http://server2: failed to find resource; http://server3: failed to retrieve from http://server3/appName/profile: Get \"http://server3/appName/profile\": connection refused"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transport := &slowTransport{delays: test.delays, statuses: test.statuses}
+			urls := []string{"http://server1", "http://server2", "http://server3"}[:len(test.delays)]
+			client, err := cloudconfigclient.New(
+				cloudconfigclient.Local(&http.Client{Transport: transport}, urls...),
+				cloudconfigclient.WithFailover(cloudconfigclient.DefaultFailoverPolicy()),
+				cloudconfigclient.WithHedging(50*time.Millisecond),
+			)
+			require.NoError(t, err)
+
+			start := time.Now()
+			source, err := client.GetConfiguration("appName", "profile")
+			require.Less(t, time.Since(start), time.Second)
+			if test.err != nil {
+				require.Error(t, err)
+				require.Equal(t, test.err.Error(), err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, test.expected, source.Name)
+			}
+			// the cancelled requests complete asynchronously
+			require.Eventually(t, func() bool {
+				_, cancelled := transport.calls()
+				return len(cancelled) == len(test.cancelled)
+			}, time.Second, 5*time.Millisecond)
+			requested, cancelled := transport.calls()
+			require.Equal(t, test.requested, requested)
+			require.Equal(t, test.cancelled, cancelled)
+		})
+	}
+}
+
+func TestWithHedging_File(t *testing.T) {
+	transport := &slowTransport{
+		delays:   map[string]time.Duration{"server1": 5 * time.Second, "server2": 0},
+		statuses: map[string]int{"server1": http.StatusOK, "server2": http.StatusOK},
+	}
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(&http.Client{Transport: transport}, "http://server1", "http://server2"),
+		cloudconfigclient.WithHedging(10*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	var actual map[string]string
+	require.NoError(t, client.GetFile("directory", "file.json", &actual))
+	require.Equal(t, map[string]string{"name": "server2"}, actual)
+}
+
+func TestWithHedging_InvalidDelay(t *testing.T) {
+	_, err := cloudconfigclient.New(
+		cloudconfigclient.Local(&http.Client{}, "http://server1"),
+		cloudconfigclient.WithHedging(0),
+	)
+	require.EqualError(t, err, "hedging delay must be greater than zero")
+}
@@ -1,4 +1,4 @@
-module github.com/Piszmog/cloudconfigclient/v2
+module github.com/Piszmog/cloudconfigclient/v3
 
 go 1.24.0
 
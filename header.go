@@ -0,0 +1,54 @@
+package cloudconfigclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// WithHeader adds the headers to every request sent to the Config Servers. Config Servers whose HTTPClient already
+// has a header with the same key keep their value. Providing WithHeader multiple times adds to the headers.
+func WithHeader(header http.Header) Option {
+	return func(c *Client) error {
+		if c.header == nil {
+			c.header = http.Header{}
+		}
+		for key, values := range header {
+			for _, value := range values {
+				c.header.Add(key, value)
+			}
+		}
+		return nil
+	}
+}
+
+// WithHeaderFunc calls fn before every request sent to the Config Servers and adds the returned headers to the
+// request. It is useful for headers whose value changes, e.g. short-lived tokens. If fn returns an error, the request
+// is not sent.
+//
+// Providing WithHeaderFunc multiple times calls every function, in order. Config Servers whose HTTPClient already has
+// a HeaderFunc keep it.
+func WithHeaderFunc(fn func(ctx context.Context) (http.Header, error)) Option {
+	return func(c *Client) error {
+		if fn == nil {
+			return errors.New("header function must not be nil")
+		}
+		c.headerFuncs = append(c.headerFuncs, fn)
+		return nil
+	}
+}
+
+// computeHeader calls the header functions of the Client and merges their headers.
+func (c *Client) computeHeader(ctx context.Context) (http.Header, error) {
+	header := http.Header{}
+	for _, fn := range c.headerFuncs {
+		h, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range h {
+			header[http.CanonicalHeaderKey(key)] = values
+		}
+	}
+	return header, nil
+}
@@ -0,0 +1,92 @@
+package cloudconfigclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Piszmog/cloudconfigclient/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []cloudconfigclient.Option
+		expected http.Header
+		err      string
+	}{
+		{
+			name: "Static",
+			options: []cloudconfigclient.Option{
+				cloudconfigclient.WithHeader(http.Header{"X-Config-Token": {"token"}}),
+				cloudconfigclient.WithHeader(http.Header{"X-Team": {"a", "b"}}),
+			},
+			expected: http.Header{"X-Config-Token": {"token"}, "X-Team": {"a", "b"}},
+		},
+		{
+			name: "Dynamic",
+			options: []cloudconfigclient.Option{
+				cloudconfigclient.WithHeader(http.Header{"X-Config-Token": {"static"}}),
+				cloudconfigclient.WithHeaderFunc(func(ctx context.Context) (http.Header, error) {
+					return http.Header{"X-Config-Token": {ctx.Value(contextKey{}).(string)}}, nil
+				}),
+			},
+			expected: http.Header{"X-Config-Token": {"value"}},
+		},
+		{
+			name: "Dynamic Error",
+			options: []cloudconfigclient.Option{
+				cloudconfigclient.WithHeaderFunc(func(ctx context.Context) (http.Header, error) {
+					return nil, errors.New("vault unavailable")
+				}),
+			},
+			err: "failed to compute headers for http://localhost:8888/appName/profile: vault unavailable",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var header http.Header
+			httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+				header = req.Header
+				return NewMockHttpResponse(http.StatusOK, configurationSource)
+			})
+			options := append([]cloudconfigclient.Option{cloudconfigclient.Local(httpClient, "http://localhost:8888")}, test.options...)
+			client, err := cloudconfigclient.New(options...)
+			require.NoError(t, err)
+
+			ctx := context.WithValue(context.Background(), contextKey{}, "value")
+			_, err = client.GetConfigurationContext(ctx, "appName", "profile")
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			for key, values := range test.expected {
+				require.Equal(t, values, header.Values(key))
+			}
+		})
+	}
+}
+
+func TestHTTPClient_Header(t *testing.T) {
+	httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+		require.Equal(t, "token", req.Header.Get("X-Config-Token"))
+		require.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+		return NewMockHttpResponse(http.StatusOK, configurationSource)
+	})
+	client := cloudconfigclient.HTTPClient{
+		Client:        httpClient,
+		BaseURL:       "http://localhost:8888",
+		Authorization: "Bearer token",
+		Header:        http.Header{"X-Config-Token": {"token"}},
+	}
+	_, err := client.GetResourceRaw([]string{"appName", "profile"}, nil)
+	require.NoError(t, err)
+}
+
+func TestWithHeaderFunc_Nil(t *testing.T) {
+	_, err := cloudconfigclient.New(cloudconfigclient.WithHeaderFunc(nil))
+	require.EqualError(t, err, "header function must not be nil")
+}
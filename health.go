@@ -0,0 +1,151 @@
+package cloudconfigclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// HealthStatus is the status of a Config Server.
+type HealthStatus string
+
+const (
+	// HealthUp is the status of a Config Server that is able to serve configurations.
+	HealthUp HealthStatus = "UP"
+	// HealthDown is the status of a Config Server that is not able to serve configurations.
+	HealthDown HealthStatus = "DOWN"
+)
+
+const (
+	healthApplicationName = "application"
+	healthProfile         = "default"
+)
+
+var actuatorHealthPaths = []string{"actuator", "health"}
+
+// ServerHealth is the health of a single Config Server.
+type ServerHealth struct {
+	// BaseURL is the base URL of the Config Server with any credentials redacted.
+	BaseURL string
+	// Status is the status of the Config Server.
+	Status HealthStatus
+	// Latency is how long the Config Server took to respond to the probes.
+	Latency time.Duration
+	// Err is the reason the Config Server is down, with any credentials of the URL redacted. Nil if the Config Server
+	// is up.
+	Err error
+}
+
+// MarshalJSON converts the ServerHealth to JSON. The latency is formatted as a duration (e.g. 1.5ms).
+func (s ServerHealth) MarshalJSON() ([]byte, error) {
+	health := struct {
+		URL     string       `json:"url"`
+		Status  HealthStatus `json:"status"`
+		Latency string       `json:"latency"`
+		Error   string       `json:"error,omitempty"`
+	}{URL: s.BaseURL, Status: s.Status, Latency: s.Latency.String()}
+	if s.Err != nil {
+		health.Error = s.Err.Error()
+	}
+	return json.Marshal(health)
+}
+
+// Health is the health of the Config Servers of a Client.
+type Health struct {
+	// Status is HealthUp if at least one Config Server is up, since the Client fails over to it.
+	Status HealthStatus `json:"status"`
+	// Servers contains the health of each Config Server, in the order the Config Servers were provided.
+	Servers []ServerHealth `json:"servers"`
+}
+
+// Health probes each Config Server and returns their health. The Config Servers are probed concurrently.
+//
+// A Config Server is probed with a request to its /actuator/health endpoint, followed by a request for the
+// configuration of the 'application' application with the 'default' profile. If the actuator endpoint is not exposed
+// (404), only the configuration request is used. A 404 for the configuration still means the Config Server is up.
+//
+// Probes are attempted once, regardless of the RetryPolicy.
+func (c *Client) Health(ctx context.Context) Health {
+	servers := c.servers()
+	health := Health{Status: HealthDown, Servers: make([]ServerHealth, len(servers))}
+	var wg sync.WaitGroup
+	for i, client := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			health.Servers[i] = client.health(ctx)
+		}()
+	}
+	wg.Wait()
+	for _, server := range health.Servers {
+		if server.Status == HealthUp {
+			health.Status = HealthUp
+		}
+	}
+	return health
+}
+
+// HealthHandler returns a http.Handler that responds with the Health of the Client as JSON. It responds with a 200 if
+// the Client is up and a 503 otherwise, so it can be used as a readiness probe.
+func (c *Client) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		health := c.Health(r.Context())
+		w.Header().Set("Content-Type", "application/json")
+		if health.Status == HealthUp {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(health)
+	})
+}
+
+func (h *HTTPClient) health(ctx context.Context) ServerHealth {
+	health := ServerHealth{BaseURL: redactURL(h.BaseURL), Status: HealthUp}
+	start := time.Now()
+	health.Err = h.probe(ctx)
+	health.Latency = time.Since(start)
+	if health.Err != nil {
+		health.Status = HealthDown
+	}
+	return health
+}
+
+func (h *HTTPClient) probe(ctx context.Context) error {
+	var actuator struct {
+		Status HealthStatus `json:"status"`
+	}
+	err := h.probeResource(ctx, actuatorHealthPaths, &actuator)
+	if err != nil && !errors.Is(err, ErrResourceNotFound) {
+		return err
+	}
+	if err == nil && actuator.Status != HealthUp {
+		return fmt.Errorf("actuator health status is '%s'", actuator.Status)
+	}
+	var source Source
+	if err = h.probeResource(ctx, []string{healthApplicationName, healthProfile}, &source); err != nil && !errors.Is(err, ErrResourceNotFound) {
+		return err
+	}
+	return nil
+}
+
+// probeResource is like GetResourceContext but attempts the request once.
+func (h *HTTPClient) probeResource(ctx context.Context, paths []string, dest any) error {
+	probe := *h
+	probe.Retry = nil
+	return probe.GetResourceContext(ctx, paths, nil, dest)
+}
+
+// redactURL replaces the password of the URL, if any, so the URL can be logged or displayed.
+func redactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return parsed.Redacted()
+}
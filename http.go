@@ -1,15 +1,19 @@
 package cloudconfigclient
 
 import (
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"path"
+	"slices"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -22,6 +26,20 @@ type HTTPClient struct {
 	// Authorization is the authorization header value for the Config Server. If not provided, no authorization header is not explicitly set.
 	// If the client is using OAuth2, the authorization header is set automatically.
 	Authorization string
+	// Retry is the policy used to retry failed requests to the Config Server. If nil, a request is attempted once.
+	Retry *RetryPolicy
+	// Header contains headers sent with every request to the Config Server.
+	Header http.Header
+	// HeaderFunc computes headers sent with every request to the Config Server, e.g. short-lived tokens. It is called
+	// with the context of the request. The headers are set after Header, so they take precedence.
+	HeaderFunc func(ctx context.Context) (http.Header, error)
+	// Zone is the zone or region the Config Server runs in, e.g. 'us-east-1'. It is used by the ZoneStrategy.
+	Zone string
+	// Priority groups the Config Servers for the ZoneStrategy. Config Servers with a lower priority are tried first.
+	Priority int
+	// Logger logs the attempts of the requests to the Config Server. If nil, nothing is logged.
+	Logger *slog.Logger
+	auth   authorizer
 }
 
 // ErrResourceNotFound is a special error that is used to propagate 404s.
@@ -35,11 +53,16 @@ const (
 // the response to the specified destination.
 //
 // Capable of unmarshalling YAML, JSON, and XML. If file type is of another type, use GetResourceRaw instead.
-func (h *HTTPClient) GetResource(paths []string, params map[string]string, dest any) (err error) {
+func (h *HTTPClient) GetResource(paths []string, params map[string]string, dest any) error {
+	return h.GetResourceContext(context.Background(), paths, params, dest)
+}
+
+// GetResourceContext is like GetResource but uses the provided context for the request.
+func (h *HTTPClient) GetResourceContext(ctx context.Context, paths []string, params map[string]string, dest any) (err error) {
 	if len(paths) == 0 {
 		return errors.New("no resource specified to be retrieved")
 	}
-	resp, err := h.Get(paths, params)
+	resp, err := h.GetContext(ctx, paths, params)
 	if err != nil {
 		return err
 	}
@@ -57,25 +80,30 @@ func (h *HTTPClient) GetResource(paths []string, params map[string]string, dest
 		if err != nil {
 			return fmt.Errorf("failed to read body with status code '%d': %w", resp.StatusCode, err)
 		}
-		return fmt.Errorf("server responded with status code '%d' and body '%s'", resp.StatusCode, b)
+		return newServerError(h.requestURL(paths, params), resp, b)
 	}
-	if err = decodeResponseBody(paths[len(paths)-1], resp, dest); err != nil {
+	if err = decodeResponseBody(paths[len(paths)-1], resp.Body, dest); err != nil {
+		h.logAttrs(ctx, slog.LevelError, "failed to decode response from Config Server",
+			slog.String("server", redactURL(h.requestURL(paths, params))),
+			slog.Int("status", resp.StatusCode),
+			slog.Any("error", err),
+		)
 		return err
 	}
 	return nil
 }
 
-func decodeResponseBody(file string, resp *http.Response, dest any) error {
+func decodeResponseBody(file string, body io.Reader, dest any) error {
 	if strings.Contains(file, ".yml") || strings.Contains(file, ".yaml") {
-		if err := yaml.NewDecoder(resp.Body).Decode(dest); err != nil {
+		if err := yaml.NewDecoder(body).Decode(dest); err != nil {
 			return fmt.Errorf(failedToDecodeMessage, err)
 		}
 	} else if strings.Contains(file, ".xml") {
-		if err := xml.NewDecoder(resp.Body).Decode(dest); err != nil {
+		if err := xml.NewDecoder(body).Decode(dest); err != nil {
 			return fmt.Errorf(failedToDecodeMessage, err)
 		}
 	} else {
-		if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
+		if err := json.NewDecoder(body).Decode(dest); err != nil {
 			return fmt.Errorf(failedToDecodeMessage, err)
 		}
 	}
@@ -84,11 +112,16 @@ func decodeResponseBody(file string, resp *http.Response, dest any) error {
 
 // GetResourceRaw performs a http.MethodGet operation. Builds the URL based on the provided paths and params. Returns
 // the byte slice response.
-func (h *HTTPClient) GetResourceRaw(paths []string, params map[string]string) (b []byte, err error) {
+func (h *HTTPClient) GetResourceRaw(paths []string, params map[string]string) ([]byte, error) {
+	return h.GetResourceRawContext(context.Background(), paths, params)
+}
+
+// GetResourceRawContext is like GetResourceRaw but uses the provided context for the request.
+func (h *HTTPClient) GetResourceRawContext(ctx context.Context, paths []string, params map[string]string) (b []byte, err error) {
 	if len(paths) == 0 {
 		return nil, errors.New("no resource specified to be retrieved")
 	}
-	resp, err := h.Get(paths, params)
+	resp, err := h.GetContext(ctx, paths, params)
 	if err != nil {
 		return nil, err
 	}
@@ -105,31 +138,134 @@ func (h *HTTPClient) GetResourceRaw(paths []string, params map[string]string) (b
 		return nil, fmt.Errorf("failed to read body with status code '%d': %w", resp.StatusCode, err)
 	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("server responded with status code '%d' and body '%s'", resp.StatusCode, b)
+		return nil, newServerError(h.requestURL(paths, params), resp, b)
 	}
 	return b, nil
 }
 
+// requestURL returns the URL built from the paths and params, for reporting errors.
+func (h *HTTPClient) requestURL(paths []string, params map[string]string) string {
+	fullURL, err := newURL(h.BaseURL, paths, params)
+	if err != nil {
+		return h.BaseURL
+	}
+	return fullURL
+}
+
 // Get performs a http.MethodGet operation. Builds the URL based on the provided paths and params.
 func (h *HTTPClient) Get(paths []string, params map[string]string) (*http.Response, error) {
+	return h.GetContext(context.Background(), paths, params)
+}
+
+// GetContext is like Get but uses the provided context for the request. The context is also used when retrieving
+// an OAuth2 token for the request.
+//
+// If the HTTPClient has a RetryPolicy, failed attempts are retried according to the policy. The response of the last
+// attempt is returned.
+func (h *HTTPClient) GetContext(ctx context.Context, paths []string, params map[string]string) (*http.Response, error) {
 	fullURL, err := newURL(h.BaseURL, paths, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create url: %w", err)
 	}
-	req, err := http.NewRequest(http.MethodGet, fullURL, nil)
+	attempts := h.Retry.attempts()
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		resp, err := h.do(ctx, fullURL)
+		h.logAttempt(ctx, fullURL, attempt, time.Since(start), resp, err)
+		if attempt >= attempts || !h.Retry.retryable(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			discard(resp)
+		}
+		backoff := h.Retry.backoff(attempt)
+		h.logAttrs(ctx, slog.LevelDebug, "retrying request to Config Server",
+			slog.String("server", redactURL(fullURL)),
+			slog.Int("attempt", attempt),
+			slog.Duration("backoff", backoff),
+		)
+		if err = sleep(ctx, backoff); err != nil {
+			return nil, fmt.Errorf("failed to retrieve from %s: %w", redactURL(fullURL), err)
+		}
+	}
+}
+
+// logAttempt logs the outcome of an attempt of a request to the Config Server. Failed attempts are logged at debug
+// level too, the Client logs whether it fails over at warn level.
+func (h *HTTPClient) logAttempt(ctx context.Context, fullURL string, attempt int, duration time.Duration, resp *http.Response, err error) {
+	if !h.log().Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("server", redactURL(fullURL)),
+		slog.Int("attempt", attempt),
+		slog.Duration("duration", duration),
+	}
+	if auth := h.redactedAuthorization(); auth != "" {
+		attrs = append(attrs, slog.String("auth", auth))
+	}
+	if err != nil {
+		h.logAttrs(ctx, slog.LevelDebug, "request to Config Server failed", append(attrs, slog.Any("error", err))...)
+		return
+	}
+	h.logAttrs(ctx, slog.LevelDebug, "Config Server responded", append(attrs, slog.Int("status", resp.StatusCode))...)
+}
+
+// do sends the request to the Config Server. If the Config Server responds with a 401 and the credentials of the
+// HTTPClient can be refreshed, the request is sent once more with fresh credentials.
+func (h *HTTPClient) do(ctx context.Context, fullURL string) (*http.Response, error) {
+	resp, err := h.send(ctx, fullURL)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	auth, ok := h.auth.(invalidator)
+	if !ok {
+		return resp, nil
+	}
+	discard(resp)
+	auth.invalidate()
+	h.logAttrs(ctx, slog.LevelInfo, "Config Server rejected the credentials, retrying with fresh credentials",
+		slog.String("server", redactURL(fullURL)),
+		slog.String("auth", h.redactedAuthorization()),
+	)
+	return h.send(ctx, fullURL)
+}
+
+func (h *HTTPClient) send(ctx context.Context, fullURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request for %s: %w", fullURL, err)
+		return nil, fmt.Errorf("failed to create request for %s: %w", redactURL(fullURL), err)
+	}
+	setHeader(req, h.Header)
+	if h.HeaderFunc != nil {
+		header, err := h.HeaderFunc(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute headers for %s: %w", redactURL(fullURL), err)
+		}
+		setHeader(req, header)
 	}
 	if h.Authorization != "" {
 		req.Header.Set("Authorization", h.Authorization)
 	}
+	if h.auth != nil {
+		if err = h.auth.authorize(ctx, req); err != nil {
+			return nil, fmt.Errorf("failed to authorize request for %s: %w", redactURL(fullURL), err)
+		}
+	}
 	response, err := h.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve from %s: %w", fullURL, err)
+		return nil, fmt.Errorf("failed to retrieve from %s: %w", redactURL(fullURL), err)
 	}
 	return response, nil
 }
 
+// setHeader replaces the values of the request's headers with the values of header.
+func setHeader(req *http.Request, header http.Header) {
+	for key, values := range header {
+		req.Header[http.CanonicalHeaderKey(key)] = slices.Clone(values)
+	}
+}
+
 func newURL(baseURL string, paths []string, params map[string]string) (string, error) {
 	parseURL, err := url.Parse(baseURL)
 	if err != nil {
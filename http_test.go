@@ -2,12 +2,13 @@ package cloudconfigclient_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
 	"net/http"
 	"testing"
 
-	"github.com/Piszmog/cloudconfigclient/v2"
+	"github.com/Piszmog/cloudconfigclient/v3"
 	"github.com/stretchr/testify/require"
 )
 
@@ -329,3 +330,22 @@ func (e errorReader) Close() error {
 type xmlResp struct {
 	Foo string `xml:"foo"`
 }
+
+func TestHTTPClient_GetContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contextKey{}, "value")
+	client := NewMockHttpClient(func(req *http.Request) *http.Response {
+		require.Equal(t, "value", req.Context().Value(contextKey{}))
+		return NewMockHttpResponse(http.StatusOK, `{"foo":"bar"}`)
+	})
+	httpClient := cloudconfigclient.HTTPClient{BaseURL: "http://something", Client: client}
+
+	var dest map[string]string
+	require.NoError(t, httpClient.GetResourceContext(ctx, []string{"file.json"}, nil, &dest))
+	require.Equal(t, map[string]string{"foo": "bar"}, dest)
+
+	b, err := httpClient.GetResourceRawContext(ctx, []string{"file.json"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, `{"foo":"bar"}`, string(b))
+}
+
+type contextKey struct{}
@@ -0,0 +1,117 @@
+package cloudconfigclient
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+)
+
+// discardLogger is used when no logger is set.
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// WithLogger logs the requests to the Config Servers with the logger. Each attempt, successful or not, is logged at
+// debug level, the failure of a Config Server and the failover to the next one at warn level, and requests that failed
+// on every Config Server at error level.
+//
+// The events have consistent attributes: 'server' is the redacted URL of the request, 'application', 'profiles' and
+// 'label' describe the requested configuration, or 'file' and 'label' the requested file, and 'status', 'duration',
+// 'attempt' and 'auth' describe the attempt. Credentials are never logged, 'auth' only contains the authorization
+// scheme, e.g. 'Bearer [REDACTED]'.
+//
+// The logger is also set on the HTTPClients of the Config Servers, unless they already have one.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) error {
+		if logger == nil {
+			return errors.New("logger must not be nil")
+		}
+		c.logger = logger
+		return nil
+	}
+}
+
+// log returns the logger of the Client, or a logger that discards the events.
+func (c *Client) log() *slog.Logger {
+	if c.logger == nil {
+		return discardLogger
+	}
+	return c.logger
+}
+
+// log returns the logger of the HTTPClient, or a logger that discards the events.
+func (h *HTTPClient) log() *slog.Logger {
+	if h.Logger == nil {
+		return discardLogger
+	}
+	return h.Logger
+}
+
+// redactedAuthorization returns the scheme of the authorization of the HTTPClient with the credentials redacted, or an
+// empty string if requests are not authorized.
+func (h *HTTPClient) redactedAuthorization() string {
+	scheme := ""
+	if h.auth != nil {
+		scheme = h.auth.scheme()
+	} else if h.Authorization != "" {
+		scheme, _, _ = strings.Cut(h.Authorization, " ")
+	}
+	if scheme == "" {
+		return ""
+	}
+	return scheme + " [REDACTED]"
+}
+
+// requestAttrsKey is the context key of the attributes of a request of the Client, e.g. the application.
+type requestAttrsKey struct{}
+
+// requestLogger returns the logger of the Client with the attributes of a request, and a context carrying the
+// attributes, so the events of the HTTPClients include them too.
+func (c *Client) requestLogger(ctx context.Context, attrs ...slog.Attr) (context.Context, *slog.Logger) {
+	return context.WithValue(ctx, requestAttrsKey{}, attrs), withAttrs(c.log(), attrs)
+}
+
+// withAttrs returns the logger with the attributes added to every event.
+func withAttrs(logger *slog.Logger, attrs []slog.Attr) *slog.Logger {
+	args := make([]any, len(attrs))
+	for i, attr := range attrs {
+		args[i] = attr
+	}
+	return logger.With(args...)
+}
+
+// configurationAttrs returns the attributes of a request for the configuration of the application.
+func configurationAttrs(source Source) []slog.Attr {
+	return []slog.Attr{
+		slog.String("application", source.Name),
+		slog.Any("profiles", source.Profiles),
+		slog.String("label", source.Label),
+	}
+}
+
+// fileAttrs returns the attributes of a request for the file at the paths, which are the application, profile,
+// optionally the label, directory and file.
+func fileAttrs(paths []string) []slog.Attr {
+	label := ""
+	if len(paths) == 5 {
+		label = paths[2]
+	}
+	return []slog.Attr{
+		slog.String("file", path.Join(paths[len(paths)-2:]...)),
+		slog.String("label", label),
+	}
+}
+
+// logAttrs logs the event with the logger of the HTTPClient. The attributes of the request of the Client, if any,
+// are added to the event.
+func (h *HTTPClient) logAttrs(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	logger := h.log()
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	if requestAttrs, ok := ctx.Value(requestAttrsKey{}).([]slog.Attr); ok {
+		attrs = append(slices.Clone(requestAttrs), attrs...)
+	}
+	logger.LogAttrs(ctx, level, msg, attrs...)
+}
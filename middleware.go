@@ -0,0 +1,118 @@
+package cloudconfigclient
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Middleware wraps the http.RoundTripper of the Config Servers, e.g. to add correlation IDs or sign requests.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc is an adapter to allow the use of ordinary functions as http.RoundTripper, e.g. in a Middleware.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// WithMiddleware wraps the transport of every Config Server the Client talks to with the middlewares. The first
+// middleware is the outermost, so it sees the requests first and the responses last. Providing WithMiddleware
+// multiple times adds to the middlewares.
+//
+// The middlewares are applied after the TLS settings and are not used for the requests to OAuth2 token servers.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *Client) error {
+		for _, middleware := range middlewares {
+			if middleware == nil {
+				return errors.New("middleware must not be nil")
+			}
+		}
+		if c.middleware == nil {
+			c.middleware = &middlewareSettings{clients: map[*http.Client]*http.Client{}}
+		}
+		c.middleware.middlewares = append(c.middleware.middlewares, middlewares...)
+		return nil
+	}
+}
+
+// WithRequestHook calls hook with every request sent to the Config Servers, e.g. to set a header. The hook is called
+// with a copy of the request, so it may modify it. If hook returns an error, the request is not sent.
+//
+// The hook is a Middleware, so it runs in the order it was provided among the other middlewares.
+func WithRequestHook(hook func(req *http.Request) error) Option {
+	if hook == nil {
+		return func(*Client) error {
+			return errors.New("request hook must not be nil")
+		}
+	}
+	return WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			if err := hook(req); err != nil {
+				return nil, err
+			}
+			return next.RoundTrip(req)
+		})
+	})
+}
+
+// WithResponseHook calls hook with the outcome of every request sent to the Config Servers, e.g. to record metrics.
+// The response is nil if err is not. The hook must not read the body of the response.
+//
+// The hook is a Middleware, so it runs in the order it was provided among the other middlewares.
+func WithResponseHook(hook func(req *http.Request, resp *http.Response, err error)) Option {
+	if hook == nil {
+		return func(*Client) error {
+			return errors.New("response hook must not be nil")
+		}
+	}
+	return WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			hook(req, resp, err)
+			return resp, err
+		})
+	})
+}
+
+// middlewareSettings are the middlewares applied to the http.Clients of the Config Servers.
+type middlewareSettings struct {
+	middlewares []Middleware
+	// clients caches the wrapped copy of each http.Client, so Config Servers sharing an http.Client share the copy.
+	// The copies map to themselves.
+	clients map[*http.Client]*http.Client
+}
+
+// client returns a copy of the http.Client whose transport is wrapped with the middlewares. A nil transport is
+// http.DefaultTransport.
+func (s *middlewareSettings) client(client *http.Client) *http.Client {
+	if wrapped, ok := s.clients[client]; ok {
+		return wrapped
+	}
+	wrapped := &http.Client{}
+	if client != nil {
+		*wrapped = *client
+	}
+	transport := wrapped.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	for i := len(s.middlewares) - 1; i >= 0; i-- {
+		transport = s.middlewares[i](transport)
+	}
+	wrapped.Transport = transport
+	s.clients[client] = wrapped
+	s.clients[wrapped] = wrapped
+	return wrapped
+}
+
+// wraps returns whether the http.Client is a wrapped copy, e.g. of a Config Server passed back to ReplaceServers. It
+// must not be wrapped again.
+func (s *middlewareSettings) wraps(client *http.Client) bool {
+	if s == nil {
+		return false
+	}
+	wrapped, ok := s.clients[client]
+	return ok && wrapped == client
+}
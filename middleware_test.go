@@ -0,0 +1,144 @@
+package cloudconfigclient_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Piszmog/cloudconfigclient/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMiddleware(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	tag := func(name string) cloudconfigclient.Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return cloudconfigclient.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				mu.Lock()
+				calls = append(calls, name+" request")
+				mu.Unlock()
+				resp, err := next.RoundTrip(req)
+				mu.Lock()
+				calls = append(calls, name+" response")
+				mu.Unlock()
+				return resp, err
+			})
+		}
+	}
+	var header http.Header
+	httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+		header = req.Header
+		return NewMockHttpResponse(http.StatusOK, configurationSource)
+	})
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(httpClient, "http://localhost:8888"),
+		cloudconfigclient.WithMiddleware(tag("first"), tag("second")),
+		cloudconfigclient.WithRequestHook(func(req *http.Request) error {
+			req.Header.Set("X-Correlation-ID", "abc")
+			return nil
+		}),
+		cloudconfigclient.WithResponseHook(func(req *http.Request, resp *http.Response, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls = append(calls, fmt.Sprintf("%s %d", req.Header.Get("X-Correlation-ID"), resp.StatusCode))
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+	require.Equal(t, "abc", header.Get("X-Correlation-ID"))
+	require.Equal(t, []string{"first request", "second request", "abc 200", "second response", "first response"}, calls)
+
+	// Config Servers passed back to the Client are not wrapped twice
+	require.NoError(t, client.ReplaceServers(client.Servers()...))
+	calls = nil
+	_, err = client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+	require.Len(t, calls, 5)
+}
+
+func TestWithMiddleware_SharedClient(t *testing.T) {
+	httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+		return NewMockHttpResponse(http.StatusOK, configurationSource)
+	})
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(httpClient, "http://server1", "http://server2"),
+		cloudconfigclient.WithMiddleware(func(next http.RoundTripper) http.RoundTripper { return next }),
+	)
+	require.NoError(t, err)
+	servers := client.Servers()
+	require.NotSame(t, httpClient, servers[0].Client)
+	require.Same(t, servers[0].Client, servers[1].Client)
+}
+
+func TestWithRequestHook_Error(t *testing.T) {
+	requested := false
+	httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+		requested = true
+		return NewMockHttpResponse(http.StatusOK, configurationSource)
+	})
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(httpClient, "http://localhost:8888"),
+		cloudconfigclient.WithRequestHook(func(req *http.Request) error {
+			return errors.New("failed to sign request")
+		}),
+	)
+	require.NoError(t, err)
+	_, err = client.GetConfiguration("appName", "profile")
+	require.ErrorContains(t, err, "failed to sign request")
+	require.False(t, requested)
+}
+
+func TestWithMiddleware_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "config-client", r.UserAgent())
+		_, _ = w.Write([]byte(configurationSource))
+	}))
+	defer server.Close()
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(&http.Client{}, server.URL),
+		cloudconfigclient.WithCAPEM(serverCAPEM(server)),
+		cloudconfigclient.WithRequestHook(func(req *http.Request) error {
+			req.Header.Set("User-Agent", "config-client")
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+	_, err = client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+}
+
+func TestWithMiddleware_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		option   cloudconfigclient.Option
+		expected string
+	}{
+		{
+			name:     "Nil Middleware",
+			option:   cloudconfigclient.WithMiddleware(nil),
+			expected: "middleware must not be nil",
+		},
+		{
+			name:     "Nil Request Hook",
+			option:   cloudconfigclient.WithRequestHook(nil),
+			expected: "request hook must not be nil",
+		},
+		{
+			name:     "Nil Response Hook",
+			option:   cloudconfigclient.WithResponseHook(nil),
+			expected: "response hook must not be nil",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := cloudconfigclient.New(cloudconfigclient.Local(&http.Client{}, "http://localhost:8888"), test.option)
+			require.EqualError(t, err, test.expected)
+		})
+	}
+}
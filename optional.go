@@ -0,0 +1,85 @@
+package cloudconfigclient
+
+import (
+	"errors"
+	"net/url"
+	"time"
+)
+
+var errOptionalFailFast = errors.New("optional and fail-fast are mutually exclusive")
+
+// WithOptional makes the configuration of the application optional, similar to Spring Boot's
+// spring.config.import=optional:configserver:.
+//
+// When no Config Server is reachable or no configuration is found, GetConfiguration and GetConfigurationWithLabel
+// return an empty Source instead of an error. The error is passed to warn, which may be nil. The empty configuration is
+// also logged as a warning with the logger set by WithLogger. If neither warn nor a logger is set, it is logged with
+// slog.Default, so an application never silently starts without its configuration. A Config Server is not reachable
+// if the request failed, it responded with a 5xx or its circuit breaker is open.
+//
+// Other errors are still returned, e.g. a Config Server rejecting the credentials or responding with a configuration
+// that cannot be decoded, so the application does not silently start without its configuration. A cancelled context
+// is also returned as an error.
+func WithOptional(warn func(err error)) Option {
+	return func(c *Client) error {
+		if c.failFast != nil {
+			return errOptionalFailFast
+		}
+		c.optional = true
+		c.onWarning = warn
+		return nil
+	}
+}
+
+// WithFailFast makes the Client strict about retrieving the configuration of the application, similar to Spring
+// Boot's spring.cloud.config.fail-fast.
+//
+// The first configuration retrieved by the Client is retried every interval until it succeeds or the window elapses.
+// This allows an application to wait on Config Servers that are starting at the same time. Afterward, failures are
+// returned immediately.
+func WithFailFast(window time.Duration, interval time.Duration) Option {
+	return func(c *Client) error {
+		if c.optional {
+			return errOptionalFailFast
+		}
+		if interval <= 0 {
+			return errors.New("fail-fast interval must be greater than zero")
+		}
+		c.failFast = &failFastSettings{window: window, interval: interval}
+		return nil
+	}
+}
+
+type failFastSettings struct {
+	window   time.Duration
+	interval time.Duration
+}
+
+// unavailable returns whether the error means that no Config Server was reachable or found the resource, as opposed
+// to a Config Server rejecting the request or responding with an invalid resource.
+func unavailable(err error) bool {
+	var failoverErr *FailoverError
+	if errors.As(err, &failoverErr) {
+		for _, serverErr := range failoverErr.Errors {
+			if !unavailable(serverErr) {
+				return false
+			}
+		}
+		return len(failoverErr.Errors) > 0
+	}
+	if errors.Is(err, ErrResourceNotFound) || errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+	var serverErr *ServerError
+	if errors.As(err, &serverErr) {
+		return serverErr.StatusCode >= 500
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+func (c *Client) warn(err error) {
+	if c.onWarning != nil {
+		c.onWarning(err)
+	}
+}
@@ -0,0 +1,228 @@
+package cloudconfigclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Piszmog/cloudconfigclient/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithOptional(t *testing.T) {
+	tests := []struct {
+		name     string
+		response *http.Response
+		warning  string
+	}{
+		{
+			name:     "Not Found",
+			response: NewMockHttpResponse(http.StatusNotFound, ""),
+			warning:  "using empty configuration for application appName: failed to find configuration for application appName with profiles [profile]",
+		},
+		{
+			name:    "Unreachable",
+			warning: "using empty configuration for application appName: failed to retrieve from http://localhost:8888/appName/profile: Get \"http://localhost:8888/appName/profile\": http: RoundTripper implementation (cloudconfigclient_test.RoundTripFunc) returned a nil *Response with a nil error",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+				return test.response
+			})
+			var warnings []string
+			client, err := cloudconfigclient.New(
+				cloudconfigclient.Local(httpClient, "http://localhost:8888"),
+				cloudconfigclient.WithOptional(func(err error) {
+					warnings = append(warnings, err.Error())
+				}),
+			)
+			require.NoError(t, err)
+
+			source, err := client.GetConfiguration("appName", "profile")
+			require.NoError(t, err)
+			require.Equal(t, cloudconfigclient.Source{Name: "appName", Profiles: []string{"profile"}}, source)
+			require.Equal(t, []string{test.warning}, warnings)
+		})
+	}
+}
+
+func TestWithOptional_Label(t *testing.T) {
+	httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+		return NewMockHttpResponse(http.StatusNotFound, "")
+	})
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(httpClient, "http://localhost:8888"),
+		cloudconfigclient.WithOptional(nil),
+	)
+	require.NoError(t, err)
+
+	source, err := client.GetConfigurationWithLabel("master", "appName", "profile")
+	require.NoError(t, err)
+	require.Equal(t, cloudconfigclient.Source{Name: "appName", Profiles: []string{"profile"}, Label: "master"}, source)
+}
+
+func TestWithOptional_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(&http.Client{}, "http://localhost:8888"),
+		cloudconfigclient.WithOptional(nil),
+	)
+	require.NoError(t, err)
+
+	_, err = client.GetConfigurationContext(ctx, "appName", "profile")
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestWithFailFast(t *testing.T) {
+	attempts := 0
+	httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+		attempts++
+		if attempts < 3 {
+			return NewMockHttpResponse(http.StatusServiceUnavailable, "")
+		}
+		return NewMockHttpResponse(http.StatusOK, configurationSource)
+	})
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(httpClient, "http://localhost:8888"),
+		cloudconfigclient.WithFailFast(time.Second, 5*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	source, err := client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+	require.Equal(t, "testConfig", source.Name)
+	require.Equal(t, 3, attempts)
+}
+
+func TestWithFailFast_WindowElapsed(t *testing.T) {
+	attempts := 0
+	httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+		attempts++
+		return NewMockHttpResponse(http.StatusNotFound, "")
+	})
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(httpClient, "http://localhost:8888"),
+		cloudconfigclient.WithFailFast(50*time.Millisecond, 10*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = client.GetConfiguration("appName", "profile")
+	require.EqualError(t, err, "failed to find configuration for application appName with profiles [profile]")
+	require.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+	require.Greater(t, attempts, 1)
+
+	// the window only applies to the first configuration
+	attempts = 0
+	_, err = client.GetConfiguration("appName", "profile")
+	require.Error(t, err)
+	require.Equal(t, 1, attempts)
+}
+
+func TestWithOptional_WithFailFast(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []cloudconfigclient.Option
+		err     error
+	}{
+		{
+			name:    "Optional Then Fail-Fast",
+			options: []cloudconfigclient.Option{cloudconfigclient.WithOptional(nil), cloudconfigclient.WithFailFast(time.Second, time.Second)},
+			err:     errors.New("optional and fail-fast are mutually exclusive"),
+		},
+		{
+			name:    "Fail-Fast Then Optional",
+			options: []cloudconfigclient.Option{cloudconfigclient.WithFailFast(time.Second, time.Second), cloudconfigclient.WithOptional(nil)},
+			err:     errors.New("optional and fail-fast are mutually exclusive"),
+		},
+		{
+			name:    "Invalid Interval",
+			options: []cloudconfigclient.Option{cloudconfigclient.WithFailFast(time.Second, 0)},
+			err:     errors.New("fail-fast interval must be greater than zero"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := cloudconfigclient.New(test.options...)
+			require.Equal(t, test.err, err)
+		})
+	}
+}
+
+func TestWithOptional_Unavailable(t *testing.T) {
+	tests := []struct {
+		name     string
+		response func() *http.Response
+	}{
+		{
+			name:     "Server Error",
+			response: func() *http.Response { return NewMockHttpResponse(http.StatusServiceUnavailable, "") },
+		},
+		{
+			name:     "Some Servers Not Found",
+			response: func() *http.Response { return NewMockHttpResponse(http.StatusNotFound, "") },
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			httpClient := NewMockHostsHttpClient(new([]string), map[string]func() *http.Response{"server1": test.response})
+			client, err := cloudconfigclient.New(
+				cloudconfigclient.Local(httpClient, "http://server1", "http://server2"),
+				cloudconfigclient.WithFailover(cloudconfigclient.DefaultFailoverPolicy()),
+				cloudconfigclient.WithOptional(nil),
+			)
+			require.NoError(t, err)
+
+			source, err := client.GetConfiguration("appName", "profile")
+			require.NoError(t, err)
+			require.Equal(t, cloudconfigclient.Source{Name: "appName", Profiles: []string{"profile"}}, source)
+		})
+	}
+}
+
+func TestWithOptional_Rejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		response func() *http.Response
+		expected string
+	}{
+		{
+			name:     "Unauthorized",
+			response: func() *http.Response { return NewMockHttpResponse(http.StatusUnauthorized, "") },
+			expected: "server responded with status code '401'",
+		},
+		{
+			name:     "Forbidden",
+			response: func() *http.Response { return NewMockHttpResponse(http.StatusForbidden, "") },
+			expected: "server responded with status code '403'",
+		},
+		{
+			name:     "Invalid Configuration",
+			response: func() *http.Response { return NewMockHttpResponse(http.StatusOK, "{invalid") },
+			expected: "failed to decode response from url",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+				return test.response()
+			})
+			var warnings []error
+			client, err := cloudconfigclient.New(
+				cloudconfigclient.Local(httpClient, "http://localhost:8888"),
+				cloudconfigclient.WithOptional(func(err error) {
+					warnings = append(warnings, err)
+				}),
+			)
+			require.NoError(t, err)
+
+			_, err = client.GetConfiguration("appName", "profile")
+			require.ErrorContains(t, err, test.expected)
+			require.Empty(t, warnings)
+		})
+	}
+}
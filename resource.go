@@ -1,7 +1,10 @@
 package cloudconfigclient
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"log/slog"
 )
 
 const (
@@ -11,77 +14,99 @@ const (
 
 var useDefaultLabel = map[string]string{"useDefaultLabel": "true"}
 
+var errFileNotFound = errors.New("failed to find file in the Config Server")
+
 // Resource interface describes how to retrieve files from the Config Server.
 type Resource interface {
 	// GetFile retrieves the specified file from the provided directory from the Config Server's default branch.
 	//
 	// The file will be deserialized into the specified interface type.
 	GetFile(directory string, file string, interfaceType any) error
+	// GetFileContext is like GetFile but uses the provided context for the requests.
+	GetFileContext(ctx context.Context, directory string, file string, interfaceType any) error
 	// GetFileFromBranch retrieves the specified file from the provided branch in the provided directory.
 	//
 	// The file will be deserialized into the specified interface type.
 	GetFileFromBranch(branch string, directory string, file string, interfaceType any) error
+	// GetFileFromBranchContext is like GetFileFromBranch but uses the provided context for the requests.
+	GetFileFromBranchContext(ctx context.Context, branch string, directory string, file string, interfaceType any) error
 	// GetFileRaw retrieves the file from the default branch as a byte slice.
 	GetFileRaw(directory string, file string) ([]byte, error)
+	// GetFileRawContext is like GetFileRaw but uses the provided context for the requests.
+	GetFileRawContext(ctx context.Context, directory string, file string) ([]byte, error)
 	// GetFileFromBranchRaw retrieves the file from the specified branch as a byte slice.
 	GetFileFromBranchRaw(branch string, directory string, file string) ([]byte, error)
+	// GetFileFromBranchRawContext is like GetFileFromBranchRaw but uses the provided context for the requests.
+	GetFileFromBranchRawContext(ctx context.Context, branch string, directory string, file string) ([]byte, error)
 }
 
 // GetFile retrieves the specified file from the provided directory from the Config Server's default branch.
 //
 // The file will be deserialized into the specified interface type.
 func (c *Client) GetFile(directory string, file string, interfaceType any) error {
-	return c.getFile([]string{defaultApplicationName, defaultApplicationProfile, directory, file}, useDefaultLabel, interfaceType)
+	return c.GetFileContext(context.Background(), directory, file, interfaceType)
+}
+
+// GetFileContext is like GetFile but uses the provided context for the requests.
+func (c *Client) GetFileContext(ctx context.Context, directory string, file string, interfaceType any) error {
+	return c.getFile(ctx, []string{defaultApplicationName, defaultApplicationProfile, directory, file}, useDefaultLabel, interfaceType)
 }
 
 // GetFileFromBranch retrieves the specified file from the provided branch in the provided directory.
 //
 // The file will be deserialized into the specified interface type.
 func (c *Client) GetFileFromBranch(branch string, directory string, file string, interfaceType any) error {
-	return c.getFile([]string{defaultApplicationName, defaultApplicationProfile, branch, directory, file}, nil, interfaceType)
+	return c.GetFileFromBranchContext(context.Background(), branch, directory, file, interfaceType)
+}
+
+// GetFileFromBranchContext is like GetFileFromBranch but uses the provided context for the requests.
+func (c *Client) GetFileFromBranchContext(ctx context.Context, branch string, directory string, file string, interfaceType any) error {
+	return c.getFile(ctx, []string{defaultApplicationName, defaultApplicationProfile, branch, directory, file}, nil, interfaceType)
 }
 
-func (c *Client) getFile(paths []string, params map[string]string, interfaceType any) error {
-	fileFound := false
-	for _, client := range c.clients {
-		if err := client.GetResource(paths, params, interfaceType); err != nil {
-			if errors.Is(err, ErrResourceNotFound) {
-				continue
-			}
-			return err
-		}
-		fileFound = true
+func (c *Client) getFile(ctx context.Context, paths []string, params map[string]string, interfaceType any) error {
+	b, err := c.getFileRaw(ctx, paths, params)
+	if err != nil {
+		return err
 	}
-	if !fileFound {
-		return errors.New("failed to find file in the Config Server")
+	if err = decodeResponseBody(paths[len(paths)-1], bytes.NewReader(b), interfaceType); err != nil {
+		ctx, logger := c.requestLogger(ctx, fileAttrs(paths)...)
+		logger.ErrorContext(ctx, "failed to decode file", slog.Any("error", err))
+		return err
 	}
 	return nil
 }
 
 // GetFileRaw retrieves the file from the default branch as a byte slice.
 func (c *Client) GetFileRaw(directory string, file string) ([]byte, error) {
-	return c.getFileRaw([]string{defaultApplicationName, defaultApplicationProfile, directory, file}, useDefaultLabel)
+	return c.GetFileRawContext(context.Background(), directory, file)
+}
+
+// GetFileRawContext is like GetFileRaw but uses the provided context for the requests.
+func (c *Client) GetFileRawContext(ctx context.Context, directory string, file string) ([]byte, error) {
+	return c.getFileRaw(ctx, []string{defaultApplicationName, defaultApplicationProfile, directory, file}, useDefaultLabel)
 }
 
 // GetFileFromBranchRaw retrieves the file from the specified branch as a byte slice.
 func (c *Client) GetFileFromBranchRaw(branch string, directory string, file string) ([]byte, error) {
-	return c.getFileRaw([]string{defaultApplicationName, defaultApplicationProfile, branch, directory, file}, nil)
+	return c.GetFileFromBranchRawContext(context.Background(), branch, directory, file)
+}
+
+// GetFileFromBranchRawContext is like GetFileFromBranchRaw but uses the provided context for the requests.
+func (c *Client) GetFileFromBranchRawContext(ctx context.Context, branch string, directory string, file string) ([]byte, error) {
+	return c.getFileRaw(ctx, []string{defaultApplicationName, defaultApplicationProfile, branch, directory, file}, nil)
 }
 
-func (c *Client) getFileRaw(paths []string, params map[string]string) (b []byte, err error) {
-	fileFound := false
-	for _, client := range c.clients {
-		b, err = client.GetResourceRaw(paths, params)
-		if err != nil {
-			if errors.Is(err, ErrResourceNotFound) {
-				continue
-			}
-			return
-		}
-		fileFound = true
+func (c *Client) getFileRaw(ctx context.Context, paths []string, params map[string]string) ([]byte, error) {
+	ctx, logger := c.requestLogger(ctx, fileAttrs(paths)...)
+	b, found, err := fetch(ctx, c, logger, func(ctx context.Context, client *HTTPClient) ([]byte, error) {
+		return client.GetResourceRawContext(ctx, paths, params)
+	})
+	if err != nil {
+		return nil, err
 	}
-	if !fileFound {
-		err = errors.New("failed to find file in the Config Server")
+	if !found {
+		return nil, errFileNotFound
 	}
-	return
+	return b, nil
 }
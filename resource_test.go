@@ -1,11 +1,12 @@
 package cloudconfigclient_test
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"testing"
 
-	"github.com/Piszmog/cloudconfigclient/v2"
+	"github.com/Piszmog/cloudconfigclient/v3"
 	"github.com/stretchr/testify/require"
 )
 
@@ -226,3 +227,49 @@ func TestClient_GetFileFromBranchRaw(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_GetFileContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+		t.Fatalf("unexpected request to %s", req.URL)
+		return nil
+	})
+	client, err := cloudconfigclient.New(cloudconfigclient.Local(httpClient, "http://localhost:8888"))
+	require.NoError(t, err)
+
+	var actual file
+	err = client.GetFileContext(ctx, "directory", "file.json", &actual)
+	require.ErrorIs(t, err, context.Canceled)
+	err = client.GetFileFromBranchContext(ctx, "branch", "directory", "file.json", &actual)
+	require.ErrorIs(t, err, context.Canceled)
+	_, err = client.GetFileRawContext(ctx, "directory", "file.txt")
+	require.ErrorIs(t, err, context.Canceled)
+	_, err = client.GetFileFromBranchRawContext(ctx, "branch", "directory", "file.txt")
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestClient_GetFile_MultipleServers(t *testing.T) {
+	var requested []string
+	httpClient := NewMockHostsHttpClient(&requested, map[string]func() *http.Response{
+		"server1": func() *http.Response { return NewMockHttpResponse(http.StatusNotFound, "") },
+		"server2": func() *http.Response { return NewMockHttpResponse(http.StatusOK, testJSONFile) },
+		"server3": func() *http.Response {
+			return NewMockHttpResponse(http.StatusOK, `{"example":{"field":"other"}}`)
+		},
+	})
+	client, err := cloudconfigclient.New(cloudconfigclient.Local(httpClient, "http://server1", "http://server2", "http://server3"))
+	require.NoError(t, err)
+
+	var actual file
+	require.NoError(t, client.GetFile("directory", "file.json", &actual))
+	// the file of the first Config Server that serves it is used, the remaining Config Servers are not queried
+	require.Equal(t, file{Example: example{Field: "value"}}, actual)
+	require.Equal(t, []string{"server1", "server2"}, requested)
+
+	requested = nil
+	b, err := client.GetFileRaw("directory", "file.json")
+	require.NoError(t, err)
+	require.Equal(t, testJSONFile, string(b))
+	require.Equal(t, []string{"server1", "server2"}, requested)
+}
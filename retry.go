@@ -0,0 +1,114 @@
+package cloudconfigclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// RetryPolicy configures how requests to a Config Server are retried. It mirrors Spring's
+// spring.cloud.config.retry.* settings.
+//
+// The wait before each retry starts at InitialInterval and grows by Multiplier after every retry, up to MaxInterval.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first. Values less than 1 are treated as 1.
+	MaxAttempts int
+	// InitialInterval is the wait before the first retry.
+	InitialInterval time.Duration
+	// MaxInterval caps the wait between retries. If zero, the wait is not capped.
+	MaxInterval time.Duration
+	// Multiplier is the factor the wait grows by after each retry. Values less than 1 are treated as 1.
+	Multiplier float64
+	// Jitter is the fraction, between 0 and 1, of each wait that is randomized. A Jitter of 0.1 results in waits
+	// within 10% of the computed interval.
+	Jitter float64
+	// RetryableStatusCodes are the response status codes that are retried.
+	RetryableStatusCodes []int
+	// RetryableError reports whether an error from sending a request is retried. If nil, every error is retried
+	// except the cancellation or expiry of the request's context.
+	RetryableError func(err error) bool
+}
+
+// DefaultRetryPolicy returns the RetryPolicy matching the defaults of Spring's spring.cloud.config.retry.* settings.
+// Network errors and the status codes 429, 502, 503 and 504 are retried.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:     6,
+		InitialInterval: time.Second,
+		MaxInterval:     2 * time.Second,
+		Multiplier:      1.1,
+		Jitter:          0.1,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// WithRetry sets the RetryPolicy used by every Config Server of the Client. Config Servers that already have a
+// RetryPolicy set on their HTTPClient keep it.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) error {
+		c.retry = &policy
+		return nil
+	}
+}
+
+func (p *RetryPolicy) attempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+func (p *RetryPolicy) retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		if p.RetryableError != nil {
+			return p.RetryableError(err)
+		}
+		return true
+	}
+	return slices.Contains(p.RetryableStatusCodes, resp.StatusCode)
+}
+
+// backoff returns the wait before the provided retry. The first retry is 1.
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	multiplier := math.Max(p.Multiplier, 1)
+	interval := float64(p.InitialInterval) * math.Pow(multiplier, float64(retry-1))
+	if p.MaxInterval > 0 {
+		interval = math.Min(interval, float64(p.MaxInterval))
+	}
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		interval += interval * jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(interval)
+}
+
+// sleep waits for the provided duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// discard drains and closes the body of a response that will not be used, so the connection can be reused.
+func discard(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,152 @@
+package cloudconfigclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Piszmog/cloudconfigclient/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	policy := cloudconfigclient.DefaultRetryPolicy()
+	require.Equal(t, 6, policy.MaxAttempts)
+	require.Equal(t, time.Second, policy.InitialInterval)
+	require.Equal(t, 2*time.Second, policy.MaxInterval)
+	require.Equal(t, 1.1, policy.Multiplier)
+	require.Equal(t, []int{429, 502, 503, 504}, policy.RetryableStatusCodes)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := cloudconfigclient.RetryPolicy{
+		InitialInterval: 100 * time.Millisecond,
+		MaxInterval:     300 * time.Millisecond,
+		Multiplier:      2,
+	}
+	require.Equal(t, 100*time.Millisecond, policy.Backoff(1))
+	require.Equal(t, 200*time.Millisecond, policy.Backoff(2))
+	require.Equal(t, 300*time.Millisecond, policy.Backoff(3))
+	require.Equal(t, 300*time.Millisecond, policy.Backoff(10))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		backoff := policy.Backoff(1)
+		require.GreaterOrEqual(t, backoff, 50*time.Millisecond)
+		require.LessOrEqual(t, backoff, 150*time.Millisecond)
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	policy := cloudconfigclient.RetryPolicy{
+		MaxAttempts:          3,
+		InitialInterval:      time.Millisecond,
+		RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+	}
+	tests := []struct {
+		name      string
+		responses []func() *http.Response
+		attempts  int
+		err       error
+	}{
+		{
+			name: "Succeeds After Retry",
+			responses: []func() *http.Response{
+				func() *http.Response { return NewMockHttpResponse(http.StatusServiceUnavailable, "") },
+				func() *http.Response { return NewMockHttpResponse(http.StatusOK, configurationSource) },
+			},
+			attempts: 2,
+		},
+		{
+			name: "Network Error Retried",
+			responses: []func() *http.Response{
+				func() *http.Response { return nil },
+				func() *http.Response { return NewMockHttpResponse(http.StatusOK, configurationSource) },
+			},
+			attempts: 2,
+		},
+		{
+			name: "Attempts Exhausted",
+			responses: []func() *http.Response{
+				func() *http.Response { return NewMockHttpResponse(http.StatusServiceUnavailable, "") },
+				func() *http.Response { return NewMockHttpResponse(http.StatusServiceUnavailable, "") },
+				func() *http.Response { return NewMockHttpResponse(http.StatusServiceUnavailable, "unavailable") },
+			},
+			attempts: 3,
+			err:      errors.New("server responded with status code '503' and body 'unavailable'"),
+		},
+		{
+			name: "Status Not Retryable",
+			responses: []func() *http.Response{
+				func() *http.Response { return NewMockHttpResponse(http.StatusInternalServerError, "") },
+			},
+			attempts: 1,
+			err:      errors.New("server responded with status code '500' and body ''"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attempts := 0
+			httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+				attempts++
+				return test.responses[attempts-1]()
+			})
+			client, err := cloudconfigclient.New(
+				cloudconfigclient.WithRetry(policy),
+				cloudconfigclient.Local(httpClient, "http://localhost:8888"),
+			)
+			require.NoError(t, err)
+			_, err = client.GetConfiguration("appName", "profile")
+			if test.err != nil {
+				require.Error(t, err)
+				require.Equal(t, test.err.Error(), err.Error())
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, test.attempts, attempts)
+		})
+	}
+}
+
+func TestWithRetry_RetryableError(t *testing.T) {
+	attempts := 0
+	httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+		attempts++
+		return nil
+	})
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(httpClient, "http://localhost:8888"),
+		cloudconfigclient.WithRetry(cloudconfigclient.RetryPolicy{
+			MaxAttempts:    3,
+			RetryableError: func(err error) bool { return false },
+		}),
+	)
+	require.NoError(t, err)
+	_, err = client.GetFileRaw("directory", "file.txt")
+	require.Error(t, err)
+	require.Equal(t, 1, attempts)
+}
+
+func TestWithRetry_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+		attempts++
+		cancel()
+		return NewMockHttpResponse(http.StatusServiceUnavailable, "")
+	})
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(httpClient, "http://localhost:8888"),
+		cloudconfigclient.WithRetry(cloudconfigclient.RetryPolicy{
+			MaxAttempts:          3,
+			InitialInterval:      time.Minute,
+			RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+		}),
+	)
+	require.NoError(t, err)
+	_, err = client.GetConfigurationContext(ctx, "appName", "profile")
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 1, attempts)
+}
@@ -0,0 +1,99 @@
+package cloudconfigclient
+
+import (
+	"errors"
+	"slices"
+)
+
+// Servers returns a snapshot of the Config Servers of the Client: the Config Servers provided by the options or
+// AddServer, followed by the Config Servers found by each discovery Option.
+func (c *Client) Servers() []*HTTPClient {
+	return c.servers()
+}
+
+func (c *Client) servers() []*HTTPClient {
+	c.serversMu.RLock()
+	defer c.serversMu.RUnlock()
+	servers := slices.Clone(c.clients)
+	for _, discovered := range c.discovered {
+		servers = append(servers, discovered...)
+	}
+	return servers
+}
+
+// AddServer adds copies of the Config Servers to the Client. The Client wide settings (e.g. WithRetry or WithHeader)
+// are applied to the copies. It is safe to call while requests are in flight; requests already in flight are not sent to the
+// added Config Servers.
+func (c *Client) AddServer(servers ...*HTTPClient) error {
+	if err := validateServers(servers); err != nil {
+		return err
+	}
+	c.serversMu.Lock()
+	defer c.serversMu.Unlock()
+	prepared, err := c.prepareCopies(servers)
+	if err != nil {
+		return err
+	}
+	c.clients = append(c.clients, prepared...)
+	return nil
+}
+
+// RemoveServer removes the Config Servers with the base URL from the Client, including Config Servers found by a
+// discovery Option until it refreshes. Returns whether a Config Server was removed. Requests already in flight to the
+// Config Server are not cancelled.
+func (c *Client) RemoveServer(baseURL string) bool {
+	c.serversMu.Lock()
+	defer c.serversMu.Unlock()
+	matches := func(server *HTTPClient) bool {
+		return server.BaseURL == baseURL
+	}
+	removed := slices.ContainsFunc(c.clients, matches)
+	c.clients = slices.DeleteFunc(c.clients, matches)
+	for i, discovered := range c.discovered {
+		removed = removed || slices.ContainsFunc(discovered, matches)
+		c.discovered[i] = slices.DeleteFunc(discovered, matches)
+	}
+	return removed
+}
+
+// ReplaceServers replaces the Config Servers provided by the options or AddServer with copies of the Config Servers.
+// The Config Servers found by a discovery Option are kept. The Client wide settings are applied to the copies.
+func (c *Client) ReplaceServers(servers ...*HTTPClient) error {
+	if err := validateServers(servers); err != nil {
+		return err
+	}
+	c.serversMu.Lock()
+	defer c.serversMu.Unlock()
+	prepared, err := c.prepareCopies(servers)
+	if err != nil {
+		return err
+	}
+	c.clients = prepared
+	return nil
+}
+
+// prepareCopies applies the Client wide settings to copies of the Config Servers. The HTTPClients of the caller are
+// not modified, since they may be in use by requests in flight, e.g. when they were returned by Servers.
+func (c *Client) prepareCopies(servers []*HTTPClient) ([]*HTTPClient, error) {
+	prepared := make([]*HTTPClient, len(servers))
+	for i, server := range servers {
+		server := *server
+		if err := c.prepare(&server); err != nil {
+			return nil, err
+		}
+		prepared[i] = &server
+	}
+	return prepared, nil
+}
+
+func validateServers(servers []*HTTPClient) error {
+	for _, server := range servers {
+		if server == nil {
+			return errors.New("server must not be nil")
+		}
+		if server.BaseURL == "" {
+			return errors.New("server must have a base URL")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package cloudconfigclient_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Piszmog/cloudconfigclient/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_AddServer(t *testing.T) {
+	retry := cloudconfigclient.DefaultRetryPolicy()
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(&http.Client{}, "http://server1"),
+		cloudconfigclient.WithRetry(retry),
+	)
+	require.NoError(t, err)
+
+	server := &cloudconfigclient.HTTPClient{BaseURL: "http://server2", Client: &http.Client{}}
+	require.NoError(t, client.AddServer(server))
+	require.Equal(t, []string{"http://server1", "http://server2"}, baseURLs(client))
+	// the Client wide settings are applied to a copy of the server
+	require.Equal(t, &retry, client.Servers()[1].Retry)
+	require.Nil(t, server.Retry)
+
+	require.EqualError(t, client.AddServer(nil), "server must not be nil")
+	require.EqualError(t, client.AddServer(&cloudconfigclient.HTTPClient{}), "server must have a base URL")
+}
+
+func TestClient_RemoveServer(t *testing.T) {
+	client, err := cloudconfigclient.New(cloudconfigclient.Local(&http.Client{}, "http://server1", "http://server2", "http://server3"))
+	require.NoError(t, err)
+	snapshot := client.Servers()
+
+	require.True(t, client.RemoveServer("http://server2"))
+	require.False(t, client.RemoveServer("http://server2"))
+	require.Equal(t, []string{"http://server1", "http://server3"}, baseURLs(client))
+	// snapshots are not affected
+	require.Len(t, snapshot, 3)
+	require.Equal(t, "http://server2", snapshot[1].BaseURL)
+}
+
+func TestClient_ReplaceServers(t *testing.T) {
+	var requested []string
+	httpClient := NewMockHostsHttpClient(&requested, map[string]func() *http.Response{
+		"server2": func() *http.Response { return NewMockHttpResponse(http.StatusOK, configurationSource) },
+	})
+	client, err := cloudconfigclient.New(cloudconfigclient.Local(httpClient, "http://server1"))
+	require.NoError(t, err)
+
+	require.NoError(t, client.ReplaceServers(&cloudconfigclient.HTTPClient{BaseURL: "http://server2", Client: httpClient}))
+	require.Equal(t, []string{"http://server2"}, baseURLs(client))
+	_, err = client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+	require.Equal(t, []string{"server2"}, requested)
+}
+
+func TestClient_Servers_Concurrent(t *testing.T) {
+	httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+		return NewMockHttpResponse(http.StatusOK, configurationSource)
+	})
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(httpClient, "http://server1"),
+		cloudconfigclient.WithFailover(cloudconfigclient.DefaultFailoverPolicy()),
+	)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetConfiguration("appName", "profile")
+			require.NoError(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			require.NoError(t, client.AddServer(&cloudconfigclient.HTTPClient{BaseURL: "http://server2", Client: httpClient}))
+			client.RemoveServer("http://server2")
+			require.NoError(t, client.ReplaceServers(&cloudconfigclient.HTTPClient{BaseURL: "http://server1", Client: httpClient}))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestClient_ReplaceServers_Concurrent(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(configurationSource))
+	}))
+	defer server.Close()
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(&http.Client{}, server.URL),
+		cloudconfigclient.WithCAPEM(serverCAPEM(server)),
+		cloudconfigclient.WithRetry(cloudconfigclient.DefaultRetryPolicy()),
+		cloudconfigclient.WithHeader(http.Header{"X-Test": []string{"test"}}),
+		cloudconfigclient.WithLogger(slog.New(slog.DiscardHandler)),
+	)
+	require.NoError(t, err)
+	transport := client.Servers()[0].Client
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetConfiguration("appName", "profile")
+			require.NoError(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			require.NoError(t, client.ReplaceServers(client.Servers()...))
+		}()
+	}
+	wg.Wait()
+	// the TLS settings are not applied again to Config Servers passed back to the Client
+	require.Same(t, transport, client.Servers()[0].Client)
+}
@@ -0,0 +1,104 @@
+package cloudconfigclient
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	// EnvironmentSpringCloudConfigURI is the environment variable of the comma separated base URLs of the Config
+	// Servers, as used by Spring Boot applications.
+	EnvironmentSpringCloudConfigURI = "SPRING_CLOUD_CONFIG_URI"
+	// EnvironmentSpringCloudConfigUsername is the environment variable of the basic authentication username.
+	EnvironmentSpringCloudConfigUsername = "SPRING_CLOUD_CONFIG_USERNAME"
+	// EnvironmentSpringCloudConfigPassword is the environment variable of the basic authentication password.
+	EnvironmentSpringCloudConfigPassword = "SPRING_CLOUD_CONFIG_PASSWORD"
+	// EnvironmentSpringCloudConfigLabel is the environment variable of the label (e.g. Git branch) to retrieve.
+	EnvironmentSpringCloudConfigLabel = "SPRING_CLOUD_CONFIG_LABEL"
+	// EnvironmentSpringApplicationName is the environment variable of the name of the application.
+	EnvironmentSpringApplicationName = "SPRING_APPLICATION_NAME"
+	// EnvironmentSpringProfilesActive is the environment variable of the comma separated active profiles.
+	EnvironmentSpringProfilesActive = "SPRING_PROFILES_ACTIVE"
+)
+
+const (
+	defaultSpringCloudConfigURI  = "http://localhost:8888"
+	defaultSpringApplicationName = "application"
+	defaultSpringProfile         = "default"
+)
+
+// FromSpringEnv creates a clients for the Config Servers configured by the environment variables used by Spring Boot
+// applications. The URLs are acquired from 'SPRING_CLOUD_CONFIG_URI', defaulting to 'http://localhost:8888' like
+// Spring does. If 'SPRING_CLOUD_CONFIG_USERNAME' is set, requests are authorized with basic authentication using it
+// and 'SPRING_CLOUD_CONFIG_PASSWORD'. Like Spring, the next URL is tried when a Config Server cannot be reached, unless
+// WithFailover sets a FailoverPolicy that does not fail over on transport errors.
+//
+// If client is nil, a new http.Client is used.
+func FromSpringEnv(client *http.Client) Option {
+	return func(c *Client) error {
+		if client == nil {
+			client = &http.Client{}
+		}
+		urls := splitEnv(EnvironmentSpringCloudConfigURI)
+		if len(urls) == 0 {
+			urls = []string{defaultSpringCloudConfigURI}
+		}
+		if username := os.Getenv(EnvironmentSpringCloudConfigUsername); username != "" {
+			return Basic(client, username, os.Getenv(EnvironmentSpringCloudConfigPassword), urls...)(c)
+		}
+		return Local(client, urls...)(c)
+	}
+}
+
+// SpringApplication identifies the configuration of an application.
+type SpringApplication struct {
+	// Name is the name of the application.
+	Name string
+	// Profiles are the active profiles of the application.
+	Profiles []string
+	// Label is the label (e.g. Git branch) of the configuration. If empty, the default label of the Config Server is
+	// used.
+	Label string
+}
+
+// SpringApplicationFromEnv resolves the SpringApplication from the environment variables used by Spring Boot
+// applications. Like Spring, the name defaults to 'application' if 'SPRING_APPLICATION_NAME' is not set and the
+// profiles default to 'default' if 'SPRING_PROFILES_ACTIVE' is not set. The label is acquired from
+// 'SPRING_CLOUD_CONFIG_LABEL'.
+func SpringApplicationFromEnv() SpringApplication {
+	app := SpringApplication{
+		Name:     os.Getenv(EnvironmentSpringApplicationName),
+		Profiles: splitEnv(EnvironmentSpringProfilesActive),
+		Label:    os.Getenv(EnvironmentSpringCloudConfigLabel),
+	}
+	if app.Name == "" {
+		app.Name = defaultSpringApplicationName
+	}
+	if len(app.Profiles) == 0 {
+		app.Profiles = []string{defaultSpringProfile}
+	}
+	return app
+}
+
+// GetConfigurationFromSpringEnv retrieves the configuration of the SpringApplication resolved by
+// SpringApplicationFromEnv.
+func (c *Client) GetConfigurationFromSpringEnv(ctx context.Context) (Source, error) {
+	app := SpringApplicationFromEnv()
+	if app.Label != "" {
+		return c.GetConfigurationWithLabelContext(ctx, app.Label, app.Name, app.Profiles...)
+	}
+	return c.GetConfigurationContext(ctx, app.Name, app.Profiles...)
+}
+
+// splitEnv splits the comma separated value of the environment variable, ignoring empty values.
+func splitEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,135 @@
+package cloudconfigclient
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SRVResolver looks up DNS SRV records. *net.Resolver implements it.
+type SRVResolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (cname string, addrs []*net.SRV, err error)
+}
+
+// SRVSettings configures the discovery of Config Servers from DNS SRV records.
+type SRVSettings struct {
+	// Service is the service of the SRV records, e.g. 'config' for '_config._tcp.example.com'. If Service and Proto
+	// are empty, Name is looked up directly.
+	Service string
+	// Proto is the protocol of the SRV records, e.g. 'tcp'.
+	Proto string
+	// Name is the domain name of the SRV records.
+	Name string
+	// Scheme is the scheme of the URLs of the Config Servers. Defaults to 'http'.
+	Scheme string
+	// Path is the path the Config Servers are served from, if any, e.g. '/config'.
+	Path string
+	// RefreshInterval is how often the SRV records are looked up again. If zero, they are looked up once.
+	RefreshInterval time.Duration
+	// OnError is called when looking up the SRV records again fails. The previously found Config Servers are kept.
+	OnError func(err error)
+	// Resolver looks up the SRV records. Defaults to net.DefaultResolver.
+	Resolver SRVResolver
+}
+
+// DNSSRV discovers the Config Servers from DNS SRV records, e.g. of a headless Kubernetes service. Every target of
+// the records is a Config Server. The Config Servers are ordered by the priority of their record, lowest first, and
+// Config Servers of the same priority are shuffled according to their weight, as described by RFC 2782.
+//
+// If a RefreshInterval is set, the Client must be closed to stop looking up the records.
+func DNSSRV(client *http.Client, settings SRVSettings) Option {
+	return func(c *Client) error {
+		if settings.Name == "" {
+			return errors.New("SRV name must be provided")
+		}
+		if settings.Scheme == "" {
+			settings.Scheme = "http"
+		}
+		if settings.Resolver == nil {
+			settings.Resolver = net.DefaultResolver
+		}
+		c.addDiscovery(&discovery{
+			name: "SRV records " + srvName(settings),
+			resolve: func(ctx context.Context) ([]*HTTPClient, error) {
+				_, records, err := settings.Resolver.LookupSRV(ctx, settings.Service, settings.Proto, settings.Name)
+				if err != nil {
+					return nil, err
+				}
+				records = orderSRV(records)
+				servers := make([]*HTTPClient, len(records))
+				for i, record := range records {
+					baseURL := url.URL{
+						Scheme: settings.Scheme,
+						Host:   net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port))),
+						Path:   settings.Path,
+					}
+					servers[i] = &HTTPClient{BaseURL: baseURL.String(), Client: client}
+				}
+				return servers, nil
+			},
+			interval: settings.RefreshInterval,
+			onError:  settings.OnError,
+		})
+		return nil
+	}
+}
+
+func srvName(settings SRVSettings) string {
+	if settings.Service == "" && settings.Proto == "" {
+		return settings.Name
+	}
+	return "_" + settings.Service + "._" + settings.Proto + "." + settings.Name
+}
+
+// orderSRV orders the records by priority, lowest first, and shuffles the records of the same priority by weight
+// as described by RFC 2782.
+func orderSRV(records []*net.SRV) []*net.SRV {
+	records = slices.Clone(records)
+	slices.SortStableFunc(records, func(a, b *net.SRV) int {
+		return cmp.Compare(a.Priority, b.Priority)
+	})
+	for start := 0; start < len(records); {
+		end := start + 1
+		for end < len(records) && records[end].Priority == records[start].Priority {
+			end++
+		}
+		shuffleByWeight(records[start:end])
+		start = end
+	}
+	return records
+}
+
+// shuffleByWeight orders the records randomly, where records with a higher weight are more likely to come first.
+func shuffleByWeight(records []*net.SRV) {
+	total := 0
+	for _, record := range records {
+		total += int(record.Weight)
+	}
+	for i := range records {
+		if total == 0 {
+			// the remaining records have no weight, so each is as likely
+			rand.Shuffle(len(records)-i, func(a, b int) {
+				records[i+a], records[i+b] = records[i+b], records[i+a]
+			})
+			return
+		}
+		pick := rand.IntN(total + 1)
+		sum := 0
+		for j := i; j < len(records); j++ {
+			sum += int(records[j].Weight)
+			if sum >= pick {
+				records[i], records[j] = records[j], records[i]
+				break
+			}
+		}
+		total -= int(records[i].Weight)
+	}
+}
@@ -0,0 +1,160 @@
+package cloudconfigclient_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Piszmog/cloudconfigclient/v3"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSRVResolver returns the records it is set with.
+type fakeSRVResolver struct {
+	mu      sync.Mutex
+	records []*net.SRV
+	err     error
+	lookups []string
+}
+
+func (f *fakeSRVResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lookups = append(f.lookups, service+"/"+proto+"/"+name)
+	return name, f.records, f.err
+}
+
+func (f *fakeSRVResolver) set(records []*net.SRV, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.records = records
+	f.err = err
+}
+
+func baseURLs(client *cloudconfigclient.Client) []string {
+	var urls []string
+	for _, server := range client.Servers() {
+		urls = append(urls, server.BaseURL)
+	}
+	return urls
+}
+
+func TestDNSSRV(t *testing.T) {
+	resolver := &fakeSRVResolver{records: []*net.SRV{
+		{Target: "config3.example.com.", Port: 8888, Priority: 20, Weight: 10},
+		{Target: "config1.example.com.", Port: 8888, Priority: 10, Weight: 10},
+		{Target: "config2.example.com.", Port: 8080, Priority: 15, Weight: 10},
+	}}
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(&http.Client{}, "http://static:8888"),
+		cloudconfigclient.DNSSRV(&http.Client{}, cloudconfigclient.SRVSettings{
+			Service:  "config",
+			Proto:    "tcp",
+			Name:     "example.com",
+			Scheme:   "https",
+			Path:     "/config",
+			Resolver: resolver,
+		}),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+
+	require.Equal(t, []string{
+		"http://static:8888",
+		"https://config1.example.com:8888/config",
+		"https://config2.example.com:8080/config",
+		"https://config3.example.com:8888/config",
+	}, baseURLs(client))
+	require.Equal(t, []string{"config/tcp/example.com"}, resolver.lookups)
+}
+
+func TestDNSSRV_Weight(t *testing.T) {
+	resolver := &fakeSRVResolver{records: []*net.SRV{
+		{Target: "light", Port: 8888, Priority: 10, Weight: 1},
+		{Target: "heavy", Port: 8888, Priority: 10, Weight: 1000},
+	}}
+	heavyFirst := 0
+	for i := 0; i < 100; i++ {
+		client, err := cloudconfigclient.New(cloudconfigclient.DNSSRV(&http.Client{}, cloudconfigclient.SRVSettings{Name: "config", Resolver: resolver}))
+		require.NoError(t, err)
+		urls := baseURLs(client)
+		require.Len(t, urls, 2)
+		if urls[0] == "http://heavy:8888" {
+			heavyFirst++
+		}
+	}
+	require.Greater(t, heavyFirst, 90)
+}
+
+func TestDNSSRV_Refresh(t *testing.T) {
+	resolver := &fakeSRVResolver{records: []*net.SRV{{Target: "config1", Port: 8888}}}
+	errs := make(chan error, 10)
+	client, err := cloudconfigclient.New(cloudconfigclient.DNSSRV(&http.Client{}, cloudconfigclient.SRVSettings{
+		Name:            "config",
+		RefreshInterval: 5 * time.Millisecond,
+		OnError: func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		},
+		Resolver: resolver,
+	}))
+	require.NoError(t, err)
+	defer client.Close()
+	require.Equal(t, []string{"http://config1:8888"}, baseURLs(client))
+
+	resolver.set([]*net.SRV{{Target: "config2", Port: 8888}}, nil)
+	require.Eventually(t, func() bool {
+		urls := baseURLs(client)
+		return len(urls) == 1 && urls[0] == "http://config2:8888"
+	}, time.Second, 5*time.Millisecond)
+
+	// the previously found Config Servers are kept when the lookup fails
+	resolver.set(nil, errors.New("no such host"))
+	require.EqualError(t, <-errs, "failed to discover Config Servers from SRV records config: no such host")
+	require.Equal(t, []string{"http://config2:8888"}, baseURLs(client))
+
+	require.NoError(t, client.Close())
+	resolver.mu.Lock()
+	lookups := len(resolver.lookups)
+	resolver.mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	resolver.mu.Lock()
+	defer resolver.mu.Unlock()
+	require.Equal(t, lookups, len(resolver.lookups))
+}
+
+func TestDNSSRV_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings cloudconfigclient.SRVSettings
+		err      string
+	}{
+		{
+			name:     "No Name",
+			settings: cloudconfigclient.SRVSettings{},
+			err:      "SRV name must be provided",
+		},
+		{
+			name:     "Lookup Failed",
+			settings: cloudconfigclient.SRVSettings{Service: "config", Proto: "tcp", Name: "example.com", Resolver: &fakeSRVResolver{err: errors.New("no such host")}},
+			err:      "failed to discover Config Servers from SRV records _config._tcp.example.com: no such host",
+		},
+		{
+			name:     "No Records",
+			settings: cloudconfigclient.SRVSettings{Name: "example.com", Resolver: &fakeSRVResolver{}},
+			err:      "failed to discover Config Servers from SRV records example.com: no Config Servers found",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := cloudconfigclient.New(cloudconfigclient.DNSSRV(&http.Client{}, test.settings))
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package cloudconfigclient
+
+import (
+	"cmp"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Strategy determines the order in which the Config Servers of a Client are tried.
+//
+// Strategies are shared by every request of a Client, so implementations must be safe for concurrent use.
+type Strategy interface {
+	// Order returns the Config Servers in the order they should be tried for a request. The provided slice must not
+	// be modified.
+	Order(servers []*HTTPClient) []*HTTPClient
+	// Report is called with the outcome of each request to a Config Server. The error is nil when the request
+	// succeeded and wraps ErrResourceNotFound when the Config Server responded with a 404.
+	Report(server *HTTPClient, err error)
+}
+
+// WithStrategy sets the Strategy used to determine the order in which the Config Servers are tried. Without it,
+// the Config Servers are tried in the order they were provided.
+func WithStrategy(strategy Strategy) Option {
+	return func(c *Client) error {
+		c.strategy = strategy
+		return nil
+	}
+}
+
+// OrderedStrategy returns a Strategy that tries the Config Servers in the order they were provided. The first Config
+// Server has the highest priority.
+func OrderedStrategy() Strategy {
+	return orderedStrategy{}
+}
+
+type orderedStrategy struct{}
+
+func (orderedStrategy) Order(servers []*HTTPClient) []*HTTPClient {
+	return servers
+}
+
+func (orderedStrategy) Report(*HTTPClient, error) {}
+
+// RoundRobinStrategy returns a Strategy that starts each request at the Config Server following the one the previous
+// request started at. The remaining Config Servers are tried in order. The first request starts at a random Config
+// Server, so Clients that only retrieve their configuration once, e.g. at startup, are spread across the Config
+// Servers.
+func RoundRobinStrategy() Strategy {
+	s := &roundRobinStrategy{}
+	s.next.Store(rand.Uint64())
+	return s
+}
+
+type roundRobinStrategy struct {
+	next atomic.Uint64
+}
+
+func (s *roundRobinStrategy) Order(servers []*HTTPClient) []*HTTPClient {
+	if len(servers) == 0 {
+		return servers
+	}
+	start := int((s.next.Add(1) - 1) % uint64(len(servers)))
+	return append(slices.Clone(servers[start:]), servers[:start]...)
+}
+
+func (s *roundRobinStrategy) Report(*HTTPClient, error) {}
+
+// RandomStrategy returns a Strategy that tries the Config Servers in a random order.
+func RandomStrategy() Strategy {
+	return randomStrategy{}
+}
+
+type randomStrategy struct{}
+
+func (randomStrategy) Order(servers []*HTTPClient) []*HTTPClient {
+	ordered := slices.Clone(servers)
+	rand.Shuffle(len(ordered), func(i, j int) {
+		ordered[i], ordered[j] = ordered[j], ordered[i]
+	})
+	return ordered
+}
+
+func (randomStrategy) Report(*HTTPClient, error) {}
+
+// LeastRecentlyFailedStrategy returns a Strategy that tries the Config Servers that never failed first, in the order
+// they were provided, followed by the Config Servers that failed, starting with the one whose last failure is the
+// oldest.
+func LeastRecentlyFailedStrategy() Strategy {
+	return &leastRecentlyFailedStrategy{failures: map[string]time.Time{}}
+}
+
+type leastRecentlyFailedStrategy struct {
+	mu       sync.Mutex
+	failures map[string]time.Time
+}
+
+func (s *leastRecentlyFailedStrategy) Order(servers []*HTTPClient) []*HTTPClient {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ordered := slices.Clone(servers)
+	slices.SortStableFunc(ordered, func(a, b *HTTPClient) int {
+		return s.failures[a.BaseURL].Compare(s.failures[b.BaseURL])
+	})
+	return ordered
+}
+
+func (s *leastRecentlyFailedStrategy) Report(server *HTTPClient, err error) {
+	if err == nil || errors.Is(err, ErrResourceNotFound) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[server.BaseURL] = time.Now()
+}
+
+// StickyStrategy returns a Strategy that keeps using the Config Server that last responded successfully until it
+// fails. The remaining Config Servers are tried in order. Until a Config Server responded successfully, requests start
+// at a random Config Server, so Clients are spread across the Config Servers.
+func StickyStrategy() Strategy {
+	return &stickyStrategy{}
+}
+
+type stickyStrategy struct {
+	mu      sync.Mutex
+	current string
+}
+
+func (s *stickyStrategy) Order(servers []*HTTPClient) []*HTTPClient {
+	s.mu.Lock()
+	current := s.current
+	s.mu.Unlock()
+	if current == "" {
+		if len(servers) == 0 {
+			return servers
+		}
+		start := rand.IntN(len(servers))
+		return append(slices.Clone(servers[start:]), servers[:start]...)
+	}
+	ordered := slices.Clone(servers)
+	slices.SortStableFunc(ordered, func(a, b *HTTPClient) int {
+		return cmp.Compare(stickyRank(a, current), stickyRank(b, current))
+	})
+	return ordered
+}
+
+func stickyRank(server *HTTPClient, current string) int {
+	if server.BaseURL == current {
+		return 0
+	}
+	return 1
+}
+
+func (s *stickyStrategy) Report(server *HTTPClient, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		s.current = server.BaseURL
+	} else if s.current == server.BaseURL && !errors.Is(err, ErrResourceNotFound) {
+		s.current = ""
+	}
+}
@@ -0,0 +1,178 @@
+package cloudconfigclient_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Piszmog/cloudconfigclient/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func newStrategyClient(t *testing.T, strategy cloudconfigclient.Strategy, requested *[]string, responses map[string]func() *http.Response) *cloudconfigclient.Client {
+	httpClient := NewMockHostsHttpClient(requested, responses)
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(httpClient, "http://server1", "http://server2", "http://server3"),
+		cloudconfigclient.WithFailover(cloudconfigclient.DefaultFailoverPolicy()),
+		cloudconfigclient.WithStrategy(strategy),
+	)
+	require.NoError(t, err)
+	return client
+}
+
+func okResponses() map[string]func() *http.Response {
+	ok := func() *http.Response { return NewMockHttpResponse(http.StatusOK, configurationSource) }
+	return map[string]func() *http.Response{"server1": ok, "server2": ok, "server3": ok}
+}
+
+func countRequests(requested []string) map[string]int {
+	counts := map[string]int{}
+	for _, host := range requested {
+		counts[host]++
+	}
+	return counts
+}
+
+func TestOrderedStrategy(t *testing.T) {
+	var requested []string
+	client := newStrategyClient(t, cloudconfigclient.OrderedStrategy(), &requested, okResponses())
+	for i := 0; i < 30; i++ {
+		_, err := client.GetConfiguration("appName", "profile")
+		require.NoError(t, err)
+	}
+	require.Equal(t, map[string]int{"server1": 30}, countRequests(requested))
+}
+
+func TestRoundRobinStrategy(t *testing.T) {
+	var requested []string
+	client := newStrategyClient(t, cloudconfigclient.RoundRobinStrategy(), &requested, okResponses())
+	for i := 0; i < 300; i++ {
+		_, err := client.GetConfiguration("appName", "profile")
+		require.NoError(t, err)
+	}
+	require.Equal(t, map[string]int{"server1": 100, "server2": 100, "server3": 100}, countRequests(requested))
+	require.NotEqual(t, requested[0], requested[1])
+	require.NotEqual(t, requested[1], requested[2])
+	require.Equal(t, requested[0], requested[3])
+}
+
+func TestRoundRobinStrategy_Failover(t *testing.T) {
+	var requested []string
+	responses := okResponses()
+	delete(responses, "server2")
+	client := newStrategyClient(t, cloudconfigclient.RoundRobinStrategy(), &requested, responses)
+	for i := 0; i < 3; i++ {
+		_, err := client.GetConfiguration("appName", "profile")
+		require.NoError(t, err)
+	}
+	// the request starting at server2 fails over to server3
+	require.Equal(t, map[string]int{"server1": 1, "server2": 1, "server3": 2}, countRequests(requested))
+}
+
+func TestStrategy_FirstRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy func() cloudconfigclient.Strategy
+	}{
+		{
+			name:     "Round Robin",
+			strategy: cloudconfigclient.RoundRobinStrategy,
+		},
+		{
+			name:     "Sticky",
+			strategy: cloudconfigclient.StickyStrategy,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// each Client only retrieves its configuration once, like most applications at startup
+			var requested []string
+			for i := 0; i < 3000; i++ {
+				client := newStrategyClient(t, test.strategy(), &requested, okResponses())
+				_, err := client.GetConfiguration("appName", "profile")
+				require.NoError(t, err)
+			}
+			counts := countRequests(requested)
+			require.Len(t, counts, 3)
+			for host, count := range counts {
+				// each server is expected to receive a third of the requests, allow for a generous deviation
+				require.InDelta(t, 1000, count, 200, host)
+			}
+		})
+	}
+}
+
+func TestRandomStrategy(t *testing.T) {
+	var requested []string
+	client := newStrategyClient(t, cloudconfigclient.RandomStrategy(), &requested, okResponses())
+	for i := 0; i < 3000; i++ {
+		_, err := client.GetConfiguration("appName", "profile")
+		require.NoError(t, err)
+	}
+	counts := countRequests(requested)
+	require.Len(t, counts, 3)
+	for host, count := range counts {
+		// each server is expected to receive a third of the requests, allow for a generous deviation
+		require.InDelta(t, 1000, count, 200, host)
+	}
+}
+
+func TestLeastRecentlyFailedStrategy(t *testing.T) {
+	var requested []string
+	responses := okResponses()
+	delete(responses, "server1")
+	client := newStrategyClient(t, cloudconfigclient.LeastRecentlyFailedStrategy(), &requested, responses)
+
+	_, err := client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+	require.Equal(t, []string{"server1", "server2"}, requested)
+
+	// server1 failed, so it is tried last
+	requested = nil
+	_, err = client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+	require.Equal(t, []string{"server2"}, requested)
+
+	// once server2 fails, server3 (never failed) is tried before server1 and server2
+	requested = nil
+	delete(responses, "server2")
+	_, err = client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+	require.Equal(t, []string{"server2", "server3"}, requested)
+
+	requested = nil
+	_, err = client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+	require.Equal(t, []string{"server3"}, requested)
+}
+
+func TestStickyStrategy(t *testing.T) {
+	var requested []string
+	serverError := func() *http.Response { return NewMockHttpResponse(http.StatusInternalServerError, "") }
+	responses := okResponses()
+	responses["server1"] = serverError
+	responses["server3"] = serverError
+	client := newStrategyClient(t, cloudconfigclient.StickyStrategy(), &requested, responses)
+
+	_, err := client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+	require.Equal(t, "server2", requested[len(requested)-1])
+
+	// server1 and server3 recovered, but server2 is kept
+	requested = nil
+	responses["server1"] = okResponses()["server1"]
+	responses["server3"] = okResponses()["server3"]
+	for i := 0; i < 10; i++ {
+		_, err = client.GetConfiguration("appName", "profile")
+		require.NoError(t, err)
+	}
+	require.Equal(t, map[string]int{"server2": 10}, countRequests(requested))
+
+	// server2 fails, so the next request sticks to server1, the next in order
+	requested = nil
+	responses["server2"] = serverError
+	_, err = client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+	_, err = client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+	require.Equal(t, []string{"server2", "server1", "server1"}, requested)
+}
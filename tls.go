@@ -0,0 +1,141 @@
+package cloudconfigclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// tlsSettings contains the TLS settings of the Client. They are applied to the requests to every Config Server and to
+// the OAuth2 token endpoints.
+type tlsSettings struct {
+	certificates []tls.Certificate
+	rootCAs      *x509.CertPool
+	minVersion   uint16
+	// clients caches the http.Client created for each provided http.Client, so Config Servers sharing a http.Client
+	// keep sharing its connections.
+	clients map[*http.Client]*http.Client
+}
+
+func (c *Client) tlsSettings() *tlsSettings {
+	if c.tls == nil {
+		c.tls = &tlsSettings{clients: map[*http.Client]*http.Client{}}
+	}
+	return c.tls
+}
+
+// WithClientCertificate presents the certificate and key from the PEM encoded files to Config Servers and OAuth2
+// token endpoints that require mutual TLS.
+func WithClientCertificate(certFile string, keyFile string) Option {
+	return func(c *Client) error {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		c.tlsSettings().certificates = append(c.tlsSettings().certificates, cert)
+		return nil
+	}
+}
+
+// WithClientCertificatePEM is like WithClientCertificate but uses the PEM encoded certificate and key.
+func WithClientCertificatePEM(certPEM []byte, keyPEM []byte) Option {
+	return func(c *Client) error {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		c.tlsSettings().certificates = append(c.tlsSettings().certificates, cert)
+		return nil
+	}
+}
+
+// WithCAFile trusts the certificate authorities in the PEM encoded files, in addition to the certificate authorities
+// of the system.
+func WithCAFile(files ...string) Option {
+	return func(c *Client) error {
+		for _, file := range files {
+			b, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read CA file: %w", err)
+			}
+			if err = c.tlsSettings().appendCAs(b); err != nil {
+				return fmt.Errorf("failed to load CA file %s: %w", file, err)
+			}
+		}
+		return nil
+	}
+}
+
+// WithCAPEM trusts the PEM encoded certificate authorities, in addition to the certificate authorities of the system.
+func WithCAPEM(pem []byte) Option {
+	return func(c *Client) error {
+		return c.tlsSettings().appendCAs(pem)
+	}
+}
+
+// WithMinTLSVersion sets the minimum TLS version, e.g. tls.VersionTLS12, used to communicate with the Config Servers
+// and OAuth2 token endpoints.
+func WithMinTLSVersion(version uint16) Option {
+	return func(c *Client) error {
+		if version < tls.VersionTLS10 || version > tls.VersionTLS13 {
+			return fmt.Errorf("unsupported TLS version %#x", version)
+		}
+		c.tlsSettings().minVersion = version
+		return nil
+	}
+}
+
+func (s *tlsSettings) appendCAs(pem []byte) error {
+	if s.rootCAs == nil {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		s.rootCAs = pool
+	}
+	if !s.rootCAs.AppendCertsFromPEM(pem) {
+		return errors.New("no certificates found in PEM")
+	}
+	return nil
+}
+
+// client returns a copy of the http.Client whose transport uses the TLS settings. The transport of the http.Client
+// must be a *http.Transport, or nil to use a copy of http.DefaultTransport.
+func (s *tlsSettings) client(client *http.Client) (*http.Client, error) {
+	if tlsClient, ok := s.clients[client]; ok {
+		return tlsClient, nil
+	}
+	tlsClient := &http.Client{}
+	if client != nil {
+		*tlsClient = *client
+	}
+	var transport *http.Transport
+	switch t := tlsClient.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return nil, fmt.Errorf("cannot apply TLS settings to transport of type %T", t)
+	}
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	if len(s.certificates) > 0 {
+		transport.TLSClientConfig.Certificates = append(transport.TLSClientConfig.Certificates, s.certificates...)
+	}
+	if s.rootCAs != nil {
+		transport.TLSClientConfig.RootCAs = s.rootCAs
+	}
+	if s.minVersion != 0 {
+		transport.TLSClientConfig.MinVersion = s.minVersion
+	}
+	tlsClient.Transport = transport
+	s.clients[client] = tlsClient
+	// a Config Server that already uses the copy, e.g. one passed back to ReplaceServers, keeps it
+	s.clients[tlsClient] = tlsClient
+	return tlsClient, nil
+}
@@ -0,0 +1,182 @@
+package cloudconfigclient_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Piszmog/cloudconfigclient/v3"
+	"github.com/stretchr/testify/require"
+)
+
+// newClientCertificate creates a self-signed client certificate and returns it PEM encoded.
+func newClientCertificate(t *testing.T) ([]byte, []byte, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		cert
+}
+
+// newMutualTLSServer creates a TLS server that requires the client certificate.
+func newMutualTLSServer(t *testing.T, clientCert *x509.Certificate, handler http.HandlerFunc) *httptest.Server {
+	server := httptest.NewUnstartedServer(handler)
+	pool := x509.NewCertPool()
+	pool.AddCert(clientCert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func serverCAPEM(server *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+}
+
+func TestWithClientCertificate(t *testing.T) {
+	certPEM, keyPEM, cert := newClientCertificate(t)
+	server := newMutualTLSServer(t, cert, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(configurationSource))
+	})
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	caFile := filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+	require.NoError(t, os.WriteFile(caFile, serverCAPEM(server), 0o600))
+
+	tests := []struct {
+		name    string
+		options []cloudconfigclient.Option
+		err     bool
+	}{
+		{
+			name: "Files",
+			options: []cloudconfigclient.Option{
+				cloudconfigclient.WithClientCertificate(certFile, keyFile),
+				cloudconfigclient.WithCAFile(caFile),
+			},
+		},
+		{
+			name: "PEM",
+			options: []cloudconfigclient.Option{
+				cloudconfigclient.WithClientCertificatePEM(certPEM, keyPEM),
+				cloudconfigclient.WithCAPEM(serverCAPEM(server)),
+				cloudconfigclient.WithMinTLSVersion(tls.VersionTLS12),
+			},
+		},
+		{
+			name:    "No Client Certificate",
+			options: []cloudconfigclient.Option{cloudconfigclient.WithCAPEM(serverCAPEM(server))},
+			err:     true,
+		},
+		{
+			name:    "Unknown CA",
+			options: []cloudconfigclient.Option{cloudconfigclient.WithClientCertificatePEM(certPEM, keyPEM)},
+			err:     true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := append([]cloudconfigclient.Option{cloudconfigclient.Local(&http.Client{}, server.URL)}, test.options...)
+			client, err := cloudconfigclient.New(options...)
+			require.NoError(t, err)
+
+			source, err := client.GetConfiguration("appName", "profile")
+			if test.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, "testConfig", source.Name)
+			}
+		})
+	}
+}
+
+func TestWithClientCertificate_OAuth2(t *testing.T) {
+	certPEM, keyPEM, cert := newClientCertificate(t)
+	tokenServer := newMutualTLSServer(t, cert, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"token","token_type":"bearer","expires_in":3600}`))
+	})
+	configServer := newMutualTLSServer(t, cert, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(configurationSource))
+	})
+
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.OAuth2(configServer.URL, "clientId", "secret", tokenServer.URL),
+		cloudconfigclient.WithClientCertificatePEM(certPEM, keyPEM),
+		cloudconfigclient.WithCAPEM(serverCAPEM(tokenServer)),
+		cloudconfigclient.WithCAPEM(serverCAPEM(configServer)),
+	)
+	require.NoError(t, err)
+
+	source, err := client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+	require.Equal(t, "testConfig", source.Name)
+}
+
+func TestWithTLS_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []cloudconfigclient.Option
+		err     string
+	}{
+		{
+			name:    "Missing Certificate File",
+			options: []cloudconfigclient.Option{cloudconfigclient.WithClientCertificate("missing.crt", "missing.key")},
+			err:     "failed to load client certificate: open missing.crt: no such file or directory",
+		},
+		{
+			name:    "Invalid CA",
+			options: []cloudconfigclient.Option{cloudconfigclient.WithCAPEM([]byte("invalid"))},
+			err:     "no certificates found in PEM",
+		},
+		{
+			name:    "Unsupported Version",
+			options: []cloudconfigclient.Option{cloudconfigclient.WithMinTLSVersion(0x0200)},
+			err:     "unsupported TLS version 0x200",
+		},
+		{
+			name: "Custom Transport",
+			options: []cloudconfigclient.Option{
+				cloudconfigclient.Local(NewMockHttpClient(nil), "http://localhost:8888"),
+				cloudconfigclient.WithMinTLSVersion(tls.VersionTLS12),
+			},
+			err: "failed to configure TLS for http://localhost:8888: cannot apply TLS settings to transport of type cloudconfigclient_test.RoundTripFunc",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := cloudconfigclient.New(test.options...)
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
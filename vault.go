@@ -0,0 +1,98 @@
+package cloudconfigclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VaultTokenHeader is the header the Config Server reads the Vault token from when it uses a Vault backend.
+const VaultTokenHeader = "X-Config-Token"
+
+// vaultPrefix is the prefix of the names of the property sources of a Vault backend, e.g. 'vault:app/profile'.
+const vaultPrefix = "vault:"
+
+// VaultSettings configures the Vault token sent to Config Servers that use a Vault backend.
+type VaultSettings struct {
+	// Token is the Vault token. If TokenProvider is set, Token is used until it expires.
+	Token string
+	// TTL is how long Token is valid. If zero, Token does not expire.
+	TTL time.Duration
+	// TokenProvider retrieves a new Vault token once the current token expired, e.g. by renewing it. It returns the
+	// token and how long it is valid, zero if it does not expire. It is called with the context of the request.
+	TokenProvider func(ctx context.Context) (token string, ttl time.Duration, err error)
+}
+
+// WithVault sends the Vault token in the X-Config-Token header of every request to the Config Servers, as required by
+// a Config Server that uses a Vault backend.
+func WithVault(settings VaultSettings) Option {
+	return func(c *Client) error {
+		if settings.Token == "" && settings.TokenProvider == nil {
+			return errors.New("a vault token or token provider must be provided")
+		}
+		token := &vaultToken{provider: settings.TokenProvider, token: settings.Token}
+		if settings.Token != "" && settings.TTL > 0 {
+			token.expiry = time.Now().Add(settings.TTL)
+		}
+		return WithHeaderFunc(token.header)(c)
+	}
+}
+
+// vaultToken caches the Vault token until it expires.
+type vaultToken struct {
+	provider func(ctx context.Context) (string, time.Duration, error)
+	mu       sync.Mutex
+	token    string
+	expiry   time.Time
+}
+
+func (v *vaultToken) header(ctx context.Context) (http.Header, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.provider != nil && (v.token == "" || (!v.expiry.IsZero() && !time.Now().Before(v.expiry))) {
+		token, ttl, err := v.provider(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve vault token: %w", err)
+		}
+		if token == "" {
+			return nil, errors.New("failed to retrieve vault token: token provider returned an empty token")
+		}
+		v.token = token
+		v.expiry = time.Time{}
+		if ttl > 0 {
+			v.expiry = time.Now().Add(ttl)
+		}
+	}
+	return http.Header{VaultTokenHeader: {v.token}}, nil
+}
+
+// IsVault reports whether the PropertySource is from a Vault backend. The names of these property sources are
+// prefixed with 'vault:', e.g. 'vault:app/profile'.
+func (p PropertySource) IsVault() bool {
+	return strings.HasPrefix(p.Name, vaultPrefix)
+}
+
+// VaultPropertySources returns the property sources from a Vault backend, in order of precedence.
+func (s *Source) VaultPropertySources() []PropertySource {
+	var propertySources []PropertySource
+	for _, propertySource := range s.PropertySources {
+		if propertySource.IsVault() {
+			propertySources = append(propertySources, propertySource)
+		}
+	}
+	return propertySources
+}
+
+// FilePropertySources returns the property sources that are files, e.g. from a Git backend, in order of precedence.
+// These are the property sources handled by HandlePropertySources.
+func (s *Source) FilePropertySources() []PropertySource {
+	var propertySources []PropertySource
+	s.HandlePropertySources(func(propertySource PropertySource) {
+		propertySources = append(propertySources, propertySource)
+	})
+	return propertySources
+}
@@ -0,0 +1,134 @@
+package cloudconfigclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Piszmog/cloudconfigclient/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithVault(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings func(provided *int) cloudconfigclient.VaultSettings
+		tokens   []string
+		provided int
+		err      string
+	}{
+		{
+			name: "Static Token",
+			settings: func(provided *int) cloudconfigclient.VaultSettings {
+				return cloudconfigclient.VaultSettings{Token: "token"}
+			},
+			tokens: []string{"token", "token", "token"},
+		},
+		{
+			name: "Token Provider",
+			settings: func(provided *int) cloudconfigclient.VaultSettings {
+				return cloudconfigclient.VaultSettings{
+					TokenProvider: func(ctx context.Context) (string, time.Duration, error) {
+						*provided++
+						return "token" + strconv.Itoa(*provided), time.Hour, nil
+					},
+				}
+			},
+			tokens:   []string{"token1", "token1", "token1"},
+			provided: 1,
+		},
+		{
+			name: "Token Renewed",
+			settings: func(provided *int) cloudconfigclient.VaultSettings {
+				return cloudconfigclient.VaultSettings{
+					Token: "initial",
+					TTL:   time.Nanosecond,
+					TokenProvider: func(ctx context.Context) (string, time.Duration, error) {
+						*provided++
+						return "token" + strconv.Itoa(*provided), time.Nanosecond, nil
+					},
+				}
+			},
+			tokens:   []string{"token1", "token2", "token3"},
+			provided: 3,
+		},
+		{
+			name: "Token Provider Error",
+			settings: func(provided *int) cloudconfigclient.VaultSettings {
+				return cloudconfigclient.VaultSettings{
+					TokenProvider: func(ctx context.Context) (string, time.Duration, error) {
+						*provided++
+						return "", 0, errors.New("vault sealed")
+					},
+				}
+			},
+			provided: 3,
+			err:      "failed to compute headers for http://localhost:8888/appName/profile: failed to retrieve vault token: vault sealed",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var tokens []string
+			httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+				tokens = append(tokens, req.Header.Get(cloudconfigclient.VaultTokenHeader))
+				return NewMockHttpResponse(http.StatusOK, configurationSource)
+			})
+			provided := 0
+			client, err := cloudconfigclient.New(
+				cloudconfigclient.Local(httpClient, "http://localhost:8888"),
+				cloudconfigclient.WithVault(test.settings(&provided)),
+			)
+			require.NoError(t, err)
+
+			for i := 0; i < 3; i++ {
+				_, err = client.GetConfiguration("appName", "profile")
+				if test.err != "" {
+					require.EqualError(t, err, test.err)
+				} else {
+					require.NoError(t, err)
+				}
+			}
+			require.Equal(t, test.tokens, tokens)
+			require.Equal(t, test.provided, provided)
+		})
+	}
+}
+
+func TestWithVault_WithHeader(t *testing.T) {
+	httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+		require.Equal(t, "token", req.Header.Get(cloudconfigclient.VaultTokenHeader))
+		require.Equal(t, "team", req.Header.Get("X-Team"))
+		return NewMockHttpResponse(http.StatusOK, configurationSource)
+	})
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(httpClient, "http://localhost:8888"),
+		cloudconfigclient.WithVault(cloudconfigclient.VaultSettings{Token: "token"}),
+		cloudconfigclient.WithHeaderFunc(func(ctx context.Context) (http.Header, error) {
+			return http.Header{"X-Team": {"team"}}, nil
+		}),
+	)
+	require.NoError(t, err)
+	_, err = client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+}
+
+func TestWithVault_NoToken(t *testing.T) {
+	_, err := cloudconfigclient.New(cloudconfigclient.WithVault(cloudconfigclient.VaultSettings{}))
+	require.EqualError(t, err, "a vault token or token provider must be provided")
+}
+
+func TestSource_VaultPropertySources(t *testing.T) {
+	vault := cloudconfigclient.PropertySource{Name: "vault:appName/profile", Source: map[string]any{"secret": "value"}}
+	vaultDefault := cloudconfigclient.PropertySource{Name: "vault:appName", Source: map[string]any{"secret": "default"}}
+	git := cloudconfigclient.PropertySource{Name: "ssh://git/repo/appName-profile.yml", Source: map[string]any{"field": "value"}}
+	credhub := cloudconfigclient.PropertySource{Name: "credhub-appName"}
+	source := cloudconfigclient.Source{PropertySources: []cloudconfigclient.PropertySource{vault, vaultDefault, git, credhub}}
+
+	require.True(t, vault.IsVault())
+	require.False(t, git.IsVault())
+	require.Equal(t, []cloudconfigclient.PropertySource{vault, vaultDefault}, source.VaultPropertySources())
+	require.Equal(t, []cloudconfigclient.PropertySource{git}, source.FilePropertySources())
+}
@@ -0,0 +1,138 @@
+package cloudconfigclient
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// latencyWeight is the weight of the latest response time in the moving average of the response times of a Config
+// Server.
+const latencyWeight = 0.3
+
+// InZone tags the Config Servers of the options with the zone and priority, e.g. the region they run in. The options
+// are the Options providing Config Servers, e.g. Local or Consul. Config Servers discovered later are tagged as well.
+//
+// The tags are used by the ZoneStrategy.
+func InZone(zone string, priority int, options ...Option) Option {
+	return func(c *Client) error {
+		if len(options) == 0 {
+			return errors.New("at least one option must be provided")
+		}
+		clients, discoveries := len(c.clients), len(c.discoveries)
+		for _, option := range options {
+			if err := option(c); err != nil {
+				return err
+			}
+		}
+		for _, client := range c.clients[clients:] {
+			client.Zone = zone
+			client.Priority = priority
+		}
+		for _, d := range c.discoveries[discoveries:] {
+			resolve := d.resolve
+			d.resolve = func(ctx context.Context) ([]*HTTPClient, error) {
+				servers, err := resolve(ctx)
+				for _, server := range servers {
+					server.Zone = zone
+					server.Priority = priority
+				}
+				return servers, err
+			}
+		}
+		return nil
+	}
+}
+
+// ZoneSettings configures the ZoneStrategy.
+type ZoneSettings struct {
+	// LocalZone is the zone the application runs in. Config Servers in the zone are tried before the Config Servers
+	// of other zones with the same priority. If empty, only the priority is used.
+	LocalZone string
+	// LowestLatency is whether to try the Config Servers of a group in the order of their observed response times,
+	// fastest first. Config Servers whose last request failed are tried last. If false, the Config Servers of a group
+	// are tried in the order they were provided.
+	LowestLatency bool
+}
+
+// ZoneStrategy returns a Strategy that groups the Config Servers by their Priority and whether they are in the local
+// zone, and tries the groups in order: lowest priority first, and the local zone before the other zones. The Config
+// Servers of the next group are only tried once every Config Server of the previous group failed with an error the
+// FailoverPolicy of the Client fails over on.
+//
+// The zone and priority of the Config Servers are set with InZone, or on the HTTPClients directly.
+func ZoneStrategy(settings ZoneSettings) Strategy {
+	return &zoneStrategy{settings: settings, latencies: make(map[string]time.Duration), failed: make(map[string]bool)}
+}
+
+type zoneStrategy struct {
+	settings  ZoneSettings
+	mu        sync.Mutex
+	latencies map[string]time.Duration
+	failed    map[string]bool
+}
+
+func (s *zoneStrategy) Order(servers []*HTTPClient) []*HTTPClient {
+	ordered := slices.Clone(servers)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	slices.SortStableFunc(ordered, func(a, b *HTTPClient) int {
+		if c := cmp.Compare(a.Priority, b.Priority); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(s.zoneRank(a), s.zoneRank(b)); c != 0 {
+			return c
+		}
+		if !s.settings.LowestLatency {
+			return 0
+		}
+		if c := cmp.Compare(failedRank(s.failed[a.BaseURL]), failedRank(s.failed[b.BaseURL])); c != 0 {
+			return c
+		}
+		// Config Servers without a response time yet come first, so every Config Server is measured
+		return cmp.Compare(s.latencies[a.BaseURL], s.latencies[b.BaseURL])
+	})
+	return ordered
+}
+
+func (s *zoneStrategy) zoneRank(server *HTTPClient) int {
+	if s.settings.LocalZone == "" || server.Zone == s.settings.LocalZone {
+		return 0
+	}
+	return 1
+}
+
+func failedRank(failed bool) int {
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+func (s *zoneStrategy) Report(server *HTTPClient, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed[server.BaseURL] = err != nil && !errors.Is(err, ErrResourceNotFound)
+}
+
+func (s *zoneStrategy) reportLatency(server *HTTPClient, latency time.Duration) {
+	if latency <= 0 {
+		latency = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous, ok := s.latencies[server.BaseURL]
+	if !ok {
+		s.latencies[server.BaseURL] = latency
+		return
+	}
+	s.latencies[server.BaseURL] = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(previous))
+}
+
+// latencyReporter is implemented by Strategies that observe the response times of the Config Servers.
+type latencyReporter interface {
+	reportLatency(server *HTTPClient, latency time.Duration)
+}
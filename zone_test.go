@@ -0,0 +1,146 @@
+package cloudconfigclient_test
+
+import (
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Piszmog/cloudconfigclient/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func newZoneClient(t *testing.T, settings cloudconfigclient.ZoneSettings, httpClient *http.Client) *cloudconfigclient.Client {
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.InZone("west", 0, cloudconfigclient.Local(httpClient, "http://west1", "http://west2")),
+		cloudconfigclient.InZone("east", 0, cloudconfigclient.Local(httpClient, "http://east1", "http://east2")),
+		cloudconfigclient.InZone("backup", 1, cloudconfigclient.Local(httpClient, "http://backup")),
+		cloudconfigclient.WithFailover(cloudconfigclient.DefaultFailoverPolicy()),
+		cloudconfigclient.WithStrategy(cloudconfigclient.ZoneStrategy(settings)),
+	)
+	require.NoError(t, err)
+	return client
+}
+
+func TestZoneStrategy(t *testing.T) {
+	ok := func() *http.Response { return NewMockHttpResponse(http.StatusOK, configurationSource) }
+	tests := []struct {
+		name      string
+		settings  cloudconfigclient.ZoneSettings
+		responses map[string]func() *http.Response
+		expected  []string
+	}{
+		{
+			name:      "Local Zone",
+			settings:  cloudconfigclient.ZoneSettings{LocalZone: "east"},
+			responses: map[string]func() *http.Response{"west1": ok, "west2": ok, "east1": ok, "east2": ok, "backup": ok},
+			expected:  []string{"east1"},
+		},
+		{
+			name:      "Local Zone Fails",
+			settings:  cloudconfigclient.ZoneSettings{LocalZone: "east"},
+			responses: map[string]func() *http.Response{"west1": ok, "west2": ok, "backup": ok},
+			expected:  []string{"east1", "east2", "west1"},
+		},
+		{
+			name:      "Priority",
+			settings:  cloudconfigclient.ZoneSettings{},
+			responses: map[string]func() *http.Response{"east2": ok, "backup": ok},
+			expected:  []string{"west1", "west2", "east1", "east2"},
+		},
+		{
+			name:      "Lower Priority",
+			settings:  cloudconfigclient.ZoneSettings{LocalZone: "east"},
+			responses: map[string]func() *http.Response{"backup": ok},
+			expected:  []string{"east1", "east2", "west1", "west2", "backup"},
+		},
+		{
+			name:     "Unknown Local Zone",
+			settings: cloudconfigclient.ZoneSettings{LocalZone: "north"},
+			responses: map[string]func() *http.Response{"west1": ok, "west2": ok, "east1": ok, "east2": ok,
+				"backup": ok},
+			expected: []string{"west1"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requested []string
+			client := newZoneClient(t, test.settings, NewMockHostsHttpClient(&requested, test.responses))
+			_, err := client.GetConfiguration("appName", "profile")
+			require.NoError(t, err)
+			require.Equal(t, test.expected, requested)
+		})
+	}
+}
+
+func TestZoneStrategy_LowestLatency(t *testing.T) {
+	var requested []string
+	httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+		requested = append(requested, req.URL.Host)
+		switch req.URL.Host {
+		case "east1":
+			time.Sleep(20 * time.Millisecond)
+		case "east2":
+		default:
+			return nil
+		}
+		return NewMockHttpResponse(http.StatusOK, configurationSource)
+	})
+	client := newZoneClient(t, cloudconfigclient.ZoneSettings{LocalZone: "east", LowestLatency: true}, httpClient)
+	for i := 0; i < 4; i++ {
+		_, err := client.GetConfiguration("appName", "profile")
+		require.NoError(t, err)
+	}
+	// each Config Server of the local zone is measured once, then the fastest is used
+	require.Equal(t, []string{"east1", "east2", "east2", "east2"}, requested)
+}
+
+func TestZoneStrategy_LowestLatencyFailed(t *testing.T) {
+	var requested []string
+	httpClient := NewMockHttpClient(func(req *http.Request) *http.Response {
+		requested = append(requested, req.URL.Host)
+		switch req.URL.Host {
+		case "east2":
+			time.Sleep(20 * time.Millisecond)
+		default:
+			return nil
+		}
+		return NewMockHttpResponse(http.StatusOK, configurationSource)
+	})
+	client := newZoneClient(t, cloudconfigclient.ZoneSettings{LocalZone: "east", LowestLatency: true}, httpClient)
+	_, err := client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+	_, err = client.GetConfiguration("appName", "profile")
+	require.NoError(t, err)
+	// the failed Config Server is tried last, even though it is faster
+	require.Equal(t, []string{"east1", "east2", "east2"}, requested)
+}
+
+func TestInZone(t *testing.T) {
+	resolver := &fakeSRVResolver{records: []*net.SRV{{Target: "config.example.com.", Port: 8888}}}
+	client, err := cloudconfigclient.New(
+		cloudconfigclient.Local(&http.Client{}, "http://untagged"),
+		cloudconfigclient.InZone("east", 1,
+			cloudconfigclient.Local(&http.Client{}, "http://static"),
+			cloudconfigclient.DNSSRV(&http.Client{}, cloudconfigclient.SRVSettings{Name: "example.com", Resolver: resolver}),
+		),
+	)
+	require.NoError(t, err)
+	var zones []string
+	var priorities []int
+	for _, server := range client.Servers() {
+		zones = append(zones, server.Zone)
+		priorities = append(priorities, server.Priority)
+	}
+	require.Equal(t, []string{"http://untagged", "http://static", "http://config.example.com:8888"}, baseURLs(client))
+	require.Equal(t, []string{"", "east", "east"}, zones)
+	require.Equal(t, []int{0, 1, 1}, priorities)
+}
+
+func TestInZone_Invalid(t *testing.T) {
+	_, err := cloudconfigclient.New(cloudconfigclient.InZone("east", 0))
+	require.EqualError(t, err, "at least one option must be provided")
+
+	_, err = cloudconfigclient.New(cloudconfigclient.InZone("east", 0, cloudconfigclient.Eureka(nil, cloudconfigclient.EurekaSettings{})))
+	require.EqualError(t, err, "at least one Eureka URL must be provided")
+}